@@ -1,7 +1,19 @@
 package flags
 
 const (
-	FlagHome               = "home"
-	FlagTrustedBlockHeight = "trusted-block-height"
-	FlagTrustedBlockHash   = "trusted-block-hash"
+	FlagHome                 = "home"
+	FlagTrustedBlockHeight   = "trusted-block-height"
+	FlagTrustedBlockHash     = "trusted-block-hash"
+	FlagTrustedBlockAuto     = "trusted-block-auto"
+	FlagUnsealed             = "unsealed"
+	FlagCollateralDir        = "collateral-dir"
+	FlagUniqueID             = "unique-id"
+	FlagSignerID             = "signer-id"
+	FlagProductID            = "product-id"
+	FlagSealPolicy           = "seal-policy"
+	FlagOracleAddress        = "oracle-address"
+	FlagRegistrationUniqueID = "registration-unique-id"
+	FlagOracleParams         = "oracle-params"
+	FlagFromBinary           = "from-binary"
+	FlagEnclaveConfig        = "enclave-config"
 )
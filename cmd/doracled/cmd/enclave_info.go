@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/medibloc/panacea-doracle/client/flags"
+	"github.com/medibloc/panacea-doracle/sgx"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	tos "github.com/tendermint/tendermint/libs/os"
+)
+
+// enclaveIdentity is the identity of an enclave, which is printed as JSON.
+type enclaveIdentity struct {
+	UniqueID        encodedBytes `json:"unique_id"`
+	SignerID        encodedBytes `json:"signer_id"`
+	ProductID       encodedBytes `json:"product_id"`
+	SecurityVersion uint         `json:"security_version"`
+	Debug           bool         `json:"debug"`
+}
+
+type encodedBytes struct {
+	Hex    string `json:"hex"`
+	Base64 string `json:"base64"`
+}
+
+func newEncodedBytes(bz []byte) encodedBytes {
+	return encodedBytes{
+		Hex:    hex.EncodeToString(bz),
+		Base64: base64.StdEncoding.EncodeToString(bz),
+	}
+}
+
+// egoEnclaveConfig is the part of the enclave.json used by 'ego sign'.
+type egoEnclaveConfig struct {
+	Exe             string `json:"exe"`
+	Key             string `json:"key"`
+	Debug           bool   `json:"debug"`
+	ProductID       uint16 `json:"productID"`
+	SecurityVersion uint   `json:"securityVersion"`
+}
+
+func enclaveInfoCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enclave-info",
+		Short: "Print the identity of the enclave as JSON",
+		Long: `Print the unique ID (MRENCLAVE), the signer ID (MRSIGNER), the product ID and the security version of the enclave as JSON.
+
+By default, the identity of this running enclave is printed.
+With --from-binary, the identity is read from the SIGSTRUCT of a binary signed by 'ego sign', without running it.
+The unique ID is the ENCLAVEHASH claimed by the signer in the SIGSTRUCT. It is not measured from the enclave image
+in the binary, so it doesn't prove that the binary was built from the source.
+To check a reproducible build, build the binary from the source, sign it by 'ego sign' with any key
+(the unique ID doesn't depend on the signing key), and compare the unique ID of your binary with the one registered on the chain.
+With --enclave-config, the enclave.json used for signing is checked against the SIGSTRUCT.
+If --from-binary is not given, the 'exe' in the enclave.json is read.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			binaryPath, err := cmd.Flags().GetString(flags.FlagFromBinary)
+			if err != nil {
+				return err
+			}
+			enclaveConfigPath, err := cmd.Flags().GetString(flags.FlagEnclaveConfig)
+			if err != nil {
+				return err
+			}
+
+			var identity *enclaveIdentity
+			if binaryPath == "" && enclaveConfigPath == "" {
+				identity, err = selfEnclaveIdentity(cmd)
+			} else {
+				identity, err = signedEnclaveIdentity(binaryPath, enclaveConfigPath)
+			}
+			if err != nil {
+				return err
+			}
+
+			identityBz, err := json.MarshalIndent(identity, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal enclave identity: %w", err)
+			}
+
+			fmt.Println(string(identityBz))
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flags.FlagFromBinary, "", "path of a binary signed by 'ego sign'")
+	cmd.Flags().String(flags.FlagEnclaveConfig, "", "path of the enclave.json used for signing the binary")
+
+	return cmd
+}
+
+// selfEnclaveIdentity returns the identity of this running enclave.
+// The config is loaded if the app is initialized, so that the enclave in the config is used.
+func selfEnclaveIdentity(cmd *cobra.Command) (*enclaveIdentity, error) {
+	homeDir, err := cmd.Flags().GetString(flags.FlagHome)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read a home flag: %w", err)
+	}
+	if tos.FileExists(getConfigPath(homeDir)) {
+		if _, err := loadConfigFromHome(cmd); err != nil {
+			return nil, err
+		}
+	}
+
+	report, err := sgx.CurrentEnclave().GetSelfReport()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get self report: %w", err)
+	}
+
+	return &enclaveIdentity{
+		UniqueID:        newEncodedBytes(report.UniqueID),
+		SignerID:        newEncodedBytes(report.SignerID),
+		ProductID:       newEncodedBytes(report.ProductID),
+		SecurityVersion: report.SecurityVersion,
+		Debug:           report.Debug,
+	}, nil
+}
+
+// signedEnclaveIdentity returns the identity of the signed binary.
+// If the enclave.json is given, it is checked against the identity.
+func signedEnclaveIdentity(binaryPath, enclaveConfigPath string) (*enclaveIdentity, error) {
+	var enclaveConfig *egoEnclaveConfig
+	if enclaveConfigPath != "" {
+		var err error
+		enclaveConfig, err = readEgoEnclaveConfig(enclaveConfigPath)
+		if err != nil {
+			return nil, err
+		}
+		if binaryPath == "" {
+			binaryPath = enclaveConfig.Exe
+		}
+	}
+
+	info, err := sgx.ReadSignedEnclaveInfo(binaryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if enclaveConfig != nil {
+		if err := checkEgoEnclaveConfig(enclaveConfig, info); err != nil {
+			return nil, fmt.Errorf("the binary doesn't match %s: %w", enclaveConfigPath, err)
+		}
+	}
+
+	return &enclaveIdentity{
+		UniqueID:        newEncodedBytes(info.UniqueID),
+		SignerID:        newEncodedBytes(info.SignerID),
+		ProductID:       newEncodedBytes(info.ProductID),
+		SecurityVersion: info.SecurityVersion,
+		Debug:           info.Debug,
+	}, nil
+}
+
+// readEgoEnclaveConfig reads the enclave.json. The paths in it are resolved relative to the enclave.json, as 'ego sign' does.
+func readEgoEnclaveConfig(path string) (*egoEnclaveConfig, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var enclaveConfig egoEnclaveConfig
+	if err := json.Unmarshal(bz, &enclaveConfig); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	if enclaveConfig.Exe != "" && !filepath.IsAbs(enclaveConfig.Exe) {
+		enclaveConfig.Exe = filepath.Join(dir, enclaveConfig.Exe)
+	}
+	if enclaveConfig.Key != "" && !filepath.IsAbs(enclaveConfig.Key) {
+		enclaveConfig.Key = filepath.Join(dir, enclaveConfig.Key)
+	}
+
+	return &enclaveConfig, nil
+}
+
+// checkEgoEnclaveConfig checks that the binary was signed with the enclave.json.
+// The signing key is checked only if it exists, because it is usually not distributed.
+func checkEgoEnclaveConfig(enclaveConfig *egoEnclaveConfig, info *sgx.SignedEnclaveInfo) error {
+	if productID := binary.LittleEndian.Uint16(info.ProductID); productID != enclaveConfig.ProductID {
+		return fmt.Errorf("productID is %d, but %d in the enclave config", productID, enclaveConfig.ProductID)
+	}
+	if info.SecurityVersion != enclaveConfig.SecurityVersion {
+		return fmt.Errorf("securityVersion is %d, but %d in the enclave config", info.SecurityVersion, enclaveConfig.SecurityVersion)
+	}
+	if info.Debug != enclaveConfig.Debug {
+		return fmt.Errorf("debug is %t, but %t in the enclave config", info.Debug, enclaveConfig.Debug)
+	}
+
+	if enclaveConfig.Key == "" || !tos.FileExists(enclaveConfig.Key) {
+		log.Infof("the signing key is not found. the signer ID is not checked against the key")
+		return nil
+	}
+
+	pubKey, err := readRSAPublicKey(enclaveConfig.Key)
+	if err != nil {
+		return err
+	}
+	signerID, err := sgx.SignerIDFromPubKey(pubKey)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(signerID, info.SignerID) {
+		return fmt.Errorf("the binary was not signed by %s", enclaveConfig.Key)
+	}
+
+	return nil
+}
+
+// readRSAPublicKey reads the RSA public key from a PEM file of a private key or a public key.
+func readRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode(bz)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %s", path)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM type in %s: %s", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return &key.PublicKey, nil
+	case *rsa.PublicKey:
+		return key, nil
+	default:
+		return nil, errors.New("the signing key is not an RSA key")
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/medibloc/panacea-doracle/client/flags"
 	"github.com/medibloc/panacea-doracle/panacea"
 
 	"github.com/cosmos/cosmos-sdk/client/input"
@@ -58,7 +57,7 @@ So please be cautious in using this command.`,
 			}
 
 			// seal and store oracle private key
-			if err := sgx.SealToFile(oraclePrivKey.Serialize(), oraclePrivKeyPath); err != nil {
+			if err := sgx.SealToFile(oraclePrivKey.Serialize(), oraclePrivKeyPath, sgx.SealedFileTypeOracleKey); err != nil {
 				log.Errorf("failed to write %s: %v", oraclePrivKeyPath, err)
 				return err
 			}
@@ -78,7 +77,7 @@ So please be cautious in using this command.`,
 			}
 
 			// get trusted block information
-			trustedBlockInfo, err := getTrustedBlockInfo(cmd)
+			trustedBlockInfo, err := getTrustedBlockInfo(cmd, conf)
 			if err != nil {
 				return fmt.Errorf("failed to get trusted block info: %w", err)
 			}
@@ -93,10 +92,7 @@ So please be cautious in using this command.`,
 			return nil
 		},
 	}
-	cmd.Flags().Int64(flags.FlagTrustedBlockHeight, 0, "Trusted block height")
-	cmd.Flags().String(flags.FlagTrustedBlockHash, "", "Trusted block hash")
-	_ = cmd.MarkFlagRequired(flags.FlagTrustedBlockHeight)
-	_ = cmd.MarkFlagRequired(flags.FlagTrustedBlockHash)
+	addTrustedBlockFlags(cmd)
 
 	return cmd
 }
@@ -42,7 +42,7 @@ func getOracleKeyCmd() *cobra.Command {
 			}
 
 			// get existing node key
-			nodePrivKeyBz, err := sgx.UnsealFromFile(nodePrivKeyPath)
+			nodePrivKeyBz, err := sgx.UnsealFromFile(nodePrivKeyPath, sgx.SealedFileTypeNodeKey)
 			if err != nil {
 				return fmt.Errorf("failed to unseal node_priv_key.sealed file: %w", err)
 			}
@@ -110,7 +110,7 @@ func getOraclePrivKey(conf *config.Config, oracleRegistration *oracletypes.Oracl
 			return fmt.Errorf("failed to decrypt the encrypted oracle private key: %w", err)
 		}
 
-		if err := sgx.SealToFile(oraclePrivKey, oraclePrivKeyPath); err != nil {
+		if err := sgx.SealToFile(oraclePrivKey, oraclePrivKeyPath, sgx.SealedFileTypeOracleKey); err != nil {
 			return fmt.Errorf("failed to seal to file: %w", err)
 		}
 
@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/cosmos/cosmos-sdk/client/input"
+	"github.com/medibloc/panacea-doracle/client/flags"
+	"github.com/medibloc/panacea-doracle/panacea"
+	"github.com/medibloc/panacea-doracle/sgx"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func lightClientCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "light-client",
+		Short: "Manage the light client store",
+	}
+
+	cmd.AddCommand(
+		lightClientStatusCmd(),
+		lightClientResetCmd(),
+		lightClientRollbackCmd(),
+		lightClientExportCmd(),
+		lightClientImportCmd(),
+		lightClientRotateDataKeyCmd(),
+	)
+
+	return cmd
+}
+
+func lightClientStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the last trusted block of the light client and its witnesses",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conf, err := loadConfigFromHome(cmd)
+			if err != nil {
+				return err
+			}
+
+			db, err := panacea.OpenLightClientDB(conf)
+			if err != nil {
+				return fmt.Errorf("failed to open light client DB: %w", err)
+			}
+			defer db.Close()
+
+			info, err := panacea.GetLightClientStoreInfo(conf, db)
+			if err != nil {
+				return fmt.Errorf("failed to get light client status: %w", err)
+			}
+
+			infoBz, err := json.MarshalIndent(info, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal light client status: %w", err)
+			}
+
+			fmt.Println(string(infoBz))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func lightClientResetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reset",
+		Short: "Reset the light client store to a new trusted block",
+		Long: `Delete all light blocks in the light client store, and initialize it with a new trusted block.
+The new trusted block is verified by the primary and witnesses in the config.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conf, err := loadConfigFromHome(cmd)
+			if err != nil {
+				return err
+			}
+
+			trustedBlockInfo, err := getTrustedBlockInfo(cmd, conf)
+			if err != nil {
+				return fmt.Errorf("failed to get trusted block info: %w", err)
+			}
+
+			// the new trusted block is verified before the store is modified,
+			// so that the store is kept if the verification fails.
+			lightBlock, err := panacea.VerifyTrustedBlock(context.Background(), conf, trustedBlockInfo)
+			if err != nil {
+				return fmt.Errorf("failed to verify the trusted block: %w", err)
+			}
+
+			if !confirm("This will delete all light blocks in the light client store.\nAre you sure to reset the light client?") {
+				log.Info("Resetting the light client is canceled.")
+				return nil
+			}
+
+			db, err := panacea.OpenLightClientDB(conf)
+			if err != nil {
+				return fmt.Errorf("failed to open light client DB: %w", err)
+			}
+
+			if err := panacea.ResetLightClientStoreTo(conf, db, lightBlock); err != nil {
+				_ = db.Close()
+				return fmt.Errorf("failed to reset light client store: %w", err)
+			}
+
+			queryClient, err := panacea.NewQueryClientWithDB(context.Background(), conf, nil, db)
+			if err != nil {
+				_ = db.Close()
+				return fmt.Errorf("failed to initialize QueryClient: %w", err)
+			}
+			defer queryClient.Close()
+
+			log.Infof("light client is reset to the trusted block. height(%d), hash(%X)", trustedBlockInfo.TrustedBlockHeight, trustedBlockInfo.TrustedBlockHash)
+			return nil
+		},
+	}
+
+	addTrustedBlockFlags(cmd)
+
+	return cmd
+}
+
+func lightClientRollbackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback [height]",
+		Short: "Roll back the light client store to an earlier height",
+		Long: `Delete all light blocks above the height from the light client store.
+Because the light client doesn't store every block, the latest light block at or below the height becomes the last trusted block.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conf, err := loadConfigFromHome(cmd)
+			if err != nil {
+				return err
+			}
+
+			height, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil || height <= 0 {
+				return fmt.Errorf("invalid height: %s", args[0])
+			}
+
+			if !confirm(fmt.Sprintf("This will delete all light blocks above height %d.\nAre you sure to roll back the light client?", height)) {
+				log.Info("Rolling back the light client is canceled.")
+				return nil
+			}
+
+			db, err := panacea.OpenLightClientDB(conf)
+			if err != nil {
+				return fmt.Errorf("failed to open light client DB: %w", err)
+			}
+			defer db.Close()
+
+			lightBlock, err := panacea.RollbackLightClientStore(conf, db, height)
+			if err != nil {
+				return fmt.Errorf("failed to roll back light client store: %w", err)
+			}
+
+			log.Infof("light client is rolled back. last trusted height(%d), hash(%X)", lightBlock.Height, lightBlock.Hash())
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func lightClientExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [snapshot-file-path]",
+		Short: "Export the light client store to a snapshot file",
+		Long: `Export all light blocks in the light client store to a snapshot file.
+By default, the snapshot is sealed, so that it can be imported only by the same enclave on the same machine.
+To start a new node from the snapshot, export it with --unsealed.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conf, err := loadConfigFromHome(cmd)
+			if err != nil {
+				return err
+			}
+
+			unsealed, err := cmd.Flags().GetBool(flags.FlagUnsealed)
+			if err != nil {
+				return err
+			}
+
+			db, err := panacea.OpenLightClientDB(conf)
+			if err != nil {
+				return fmt.Errorf("failed to open light client DB: %w", err)
+			}
+			defer db.Close()
+
+			snapshot, err := panacea.ExportLightClientStore(conf, db)
+			if err != nil {
+				return fmt.Errorf("failed to export light client store: %w", err)
+			}
+
+			snapshotBz, err := json.Marshal(snapshot)
+			if err != nil {
+				return fmt.Errorf("failed to marshal snapshot: %w", err)
+			}
+
+			if unsealed {
+				if err := os.WriteFile(args[0], snapshotBz, 0600); err != nil {
+					return fmt.Errorf("failed to write %s: %w", args[0], err)
+				}
+			} else if err := sgx.SealToFile(snapshotBz, args[0], sgx.SealedFileTypeLightClientSnapshot); err != nil {
+				return err
+			}
+
+			log.Infof("%d light blocks are exported to %s", len(snapshot.LightBlocks), args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool(flags.FlagUnsealed, false, "Export the snapshot without sealing")
+
+	return cmd
+}
+
+func lightClientImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import [snapshot-file-path]",
+		Short: "Import a snapshot file to the light client store",
+		Long: `Replace all light blocks in the light client store with the light blocks in a snapshot file.
+The first light block in the snapshot is trusted as it is, like --trusted-block-height and --trusted-block-hash,
+and the last light block is verified with the primary and witnesses in the config.
+So please import only a snapshot from a trusted source, and run this command while the chain is reachable.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conf, err := loadConfigFromHome(cmd)
+			if err != nil {
+				return err
+			}
+
+			unsealed, err := cmd.Flags().GetBool(flags.FlagUnsealed)
+			if err != nil {
+				return err
+			}
+
+			var snapshotBz []byte
+			if unsealed {
+				snapshotBz, err = os.ReadFile(args[0])
+			} else {
+				snapshotBz, err = sgx.UnsealFromFile(args[0], sgx.SealedFileTypeLightClientSnapshot)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read snapshot: %w", err)
+			}
+
+			var snapshot panacea.LightClientSnapshot
+			if err := json.Unmarshal(snapshotBz, &snapshot); err != nil {
+				return fmt.Errorf("failed to unmarshal snapshot: %w", err)
+			}
+
+			if !confirm("This will replace all light blocks in the light client store.\nAre you sure to import the snapshot?") {
+				log.Info("Importing the snapshot is canceled.")
+				return nil
+			}
+
+			db, err := panacea.OpenLightClientDB(conf)
+			if err != nil {
+				return fmt.Errorf("failed to open light client DB: %w", err)
+			}
+			defer db.Close()
+
+			lightBlock, err := panacea.ImportLightClientStore(context.Background(), conf, db, &snapshot)
+			if err != nil {
+				return fmt.Errorf("failed to import snapshot: %w", err)
+			}
+
+			log.Infof("%d light blocks are imported. last trusted height(%d), hash(%X)", len(snapshot.LightBlocks), lightBlock.Height, lightBlock.Hash())
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool(flags.FlagUnsealed, false, "Import the snapshot which is not sealed")
+
+	return cmd
+}
+
+func lightClientRotateDataKeyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate-data-key",
+		Short: "Rotate the data key which encrypts the light client store",
+		Long: `Generate a new data key, and re-encrypt all values in the light client store with it.
+The old data key is removed after all values are re-encrypted.
+The oracle must be stopped while rotating the data key.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conf, err := loadConfigFromHome(cmd)
+			if err != nil {
+				return err
+			}
+
+			version, count, err := panacea.RotateLightClientDataKey(conf)
+			if err != nil {
+				return fmt.Errorf("failed to rotate data key: %w", err)
+			}
+
+			log.Infof("data key is rotated to version(%d). %d values are re-encrypted", version, count)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// confirm asks the user for confirmation and returns true only if the user confirms.
+func confirm(prompt string) bool {
+	buf := bufio.NewReader(os.Stdin)
+	ok, err := input.GetConfirmation(prompt, buf, os.Stderr)
+	if err != nil {
+		log.Errorf("failed to get confirmation: %v", err)
+		return false
+	}
+
+	return ok
+}
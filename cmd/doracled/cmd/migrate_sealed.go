@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/medibloc/panacea-doracle/client/flags"
+	"github.com/medibloc/panacea-doracle/panacea"
+	"github.com/medibloc/panacea-doracle/sgx"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func migrateSealedCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate-sealed",
+		Short: "Re-seal the sealed files with a seal policy",
+		Long: `Re-seal the oracle key, the node key and the data key of the light client store with a seal policy.
+
+Files sealed with the unique key cannot be unsealed by a new binary after an upgrade.
+To keep them during the upgrade window, run this with '--seal-policy product' by the old binary before the upgrade,
+so that the new binary signed by the same signer can unseal them.
+After the upgrade, run this with '--seal-policy unique' by the new binary, if the unique key is preferred.
+
+The oracle must be stopped while migrating the sealed files.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conf, err := loadConfigFromHome(cmd)
+			if err != nil {
+				return err
+			}
+
+			policy, err := cmd.Flags().GetString(flags.FlagSealPolicy)
+			if err != nil {
+				return err
+			}
+			if policy == "" {
+				policy = conf.Enclave.SealPolicy
+			}
+
+			sealedFiles := []struct {
+				path     string
+				fileType sgx.SealedFileType
+			}{
+				{conf.AbsOraclePrivKeyPath(), sgx.SealedFileTypeOracleKey},
+				{conf.AbsNodePrivKeyPath(), sgx.SealedFileTypeNodeKey},
+			}
+			for _, sealedFile := range sealedFiles {
+				path := sealedFile.path
+				prevPolicy, err := sgx.MigrateSealedFile(path, sealedFile.fileType, policy)
+				if errors.Is(err, os.ErrNotExist) {
+					log.Infof("%s doesn't exist. skipping", path)
+					continue
+				} else if err != nil {
+					return fmt.Errorf("failed to migrate %s: %w", path, err)
+				}
+				log.Infof("%s is migrated from the %s key to the %s key", path, prevPolicy, policy)
+			}
+
+			count, err := panacea.MigrateLightClientDataKey(conf, policy)
+			if errors.Is(err, os.ErrNotExist) {
+				log.Infof("the data key of the light client store doesn't exist. skipping")
+			} else if err != nil {
+				return fmt.Errorf("failed to migrate the data key of the light client store: %w", err)
+			} else {
+				log.Infof("the data key of the light client store is migrated to the %s key. %d values are re-encrypted", policy, count)
+			}
+
+			if policy != conf.Enclave.SealPolicy {
+				log.Warnf("the seal policy in the config is '%s'. files sealed later will use it, unless the config is changed", conf.Enclave.SealPolicy)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flags.FlagSealPolicy, "", "seal policy to re-seal files with: unique or product (default: seal-policy in the config)")
+
+	return cmd
+}
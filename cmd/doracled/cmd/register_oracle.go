@@ -10,9 +10,9 @@ import (
 	"io"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	"github.com/edgelesssys/ego/enclave"
 	oracletypes "github.com/medibloc/panacea-core/v2/x/oracle/types"
 	"github.com/medibloc/panacea-doracle/client/flags"
+	"github.com/medibloc/panacea-doracle/config"
 	"github.com/medibloc/panacea-doracle/crypto"
 	"github.com/medibloc/panacea-doracle/panacea"
 	"github.com/medibloc/panacea-doracle/sgx"
@@ -39,7 +39,7 @@ func registerOracleCmd() *cobra.Command {
 			}
 
 			// get trusted block information
-			trustedBlockInfo, err := getTrustedBlockInfo(cmd)
+			trustedBlockInfo, err := getTrustedBlockInfo(cmd, conf)
 			if err != nil {
 				return fmt.Errorf("failed to get trusted block info: %w", err)
 			}
@@ -63,7 +63,10 @@ func registerOracleCmd() *cobra.Command {
 				return fmt.Errorf("failed to generate node key pair: %w", err)
 			}
 
-			report, _ := enclave.VerifyRemoteReport(nodePubKeyRemoteReport)
+			report, err := sgx.ParseRemoteReport(nodePubKeyRemoteReport)
+			if err != nil {
+				return fmt.Errorf("failed to parse remote report of node key: %w", err)
+			}
 			uniqueID := hex.EncodeToString(report.UniqueID)
 
 			nonce := make([]byte, 12)
@@ -103,22 +106,38 @@ func registerOracleCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().Int64(flags.FlagTrustedBlockHeight, 0, "Trusted block height")
-	cmd.Flags().String(flags.FlagTrustedBlockHash, "", "Trusted block hash")
-	_ = cmd.MarkFlagRequired(flags.FlagTrustedBlockHeight)
-	_ = cmd.MarkFlagRequired(flags.FlagTrustedBlockHash)
+	addTrustedBlockFlags(cmd)
 
 	return cmd
 }
 
-// getTrustedBlockInfo gets trusted block height and hash from cmd flags
-func getTrustedBlockInfo(cmd *cobra.Command) (*panacea.TrustedBlockInfo, error) {
+// addTrustedBlockFlags adds flags to specify the trusted block, or to discover it automatically
+func addTrustedBlockFlags(cmd *cobra.Command) {
+	cmd.Flags().Int64(flags.FlagTrustedBlockHeight, 0, "Trusted block height")
+	cmd.Flags().String(flags.FlagTrustedBlockHash, "", "Trusted block hash")
+	cmd.Flags().Bool(flags.FlagTrustedBlockAuto, false, "Use the latest block agreed by the light client primary and all witnesses as a trusted block")
+	cmd.MarkFlagsRequiredTogether(flags.FlagTrustedBlockHeight, flags.FlagTrustedBlockHash)
+	cmd.MarkFlagsMutuallyExclusive(flags.FlagTrustedBlockAuto, flags.FlagTrustedBlockHeight)
+	cmd.MarkFlagsMutuallyExclusive(flags.FlagTrustedBlockAuto, flags.FlagTrustedBlockHash)
+}
+
+// getTrustedBlockInfo gets trusted block height and hash from cmd flags.
+// If the auto flag is set, the trusted block is discovered from the light client primary and witnesses.
+func getTrustedBlockInfo(cmd *cobra.Command, conf *config.Config) (*panacea.TrustedBlockInfo, error) {
+	auto, err := cmd.Flags().GetBool(flags.FlagTrustedBlockAuto)
+	if err != nil {
+		return nil, err
+	}
+	if auto {
+		return discoverTrustedBlockInfo(cmd.Context(), conf)
+	}
+
 	trustedBlockHeight, err := cmd.Flags().GetInt64(flags.FlagTrustedBlockHeight)
 	if err != nil {
 		return nil, err
 	}
 	if trustedBlockHeight == 0 {
-		return nil, fmt.Errorf("trusted block height cannot be zero")
+		return nil, fmt.Errorf("trusted block height cannot be zero. use --%s to discover the trusted block automatically", flags.FlagTrustedBlockAuto)
 	}
 
 	trustedBlockHashStr, err := cmd.Flags().GetString(flags.FlagTrustedBlockHash)
@@ -140,6 +159,31 @@ func getTrustedBlockInfo(cmd *cobra.Command) (*panacea.TrustedBlockInfo, error)
 	}, nil
 }
 
+// discoverTrustedBlockInfo discovers the trusted block, and asks the user to confirm it
+func discoverTrustedBlockInfo(ctx context.Context, conf *config.Config) (*panacea.TrustedBlockInfo, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	trustedBlockInfo, lightBlock, err := panacea.DiscoverTrustedBlockInfo(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("The trusted block is agreed by the primary and %d witness(es).\nheight: %d\nhash: %X\ntime: %s\n",
+		len(conf.Panacea.LightClientWitnessAddrs),
+		trustedBlockInfo.TrustedBlockHeight,
+		trustedBlockInfo.TrustedBlockHash,
+		lightBlock.Time,
+	)
+
+	if !confirm("Are you sure to use this block as a trusted block?") {
+		return nil, errors.New("the trusted block is not confirmed")
+	}
+
+	return trustedBlockInfo, nil
+}
+
 // generateNodeKey generates random node key and its remote report
 // And the generated private key is sealed and stored
 func generateNodeKey(nodePrivKeyPath string) ([]byte, []byte, error) {
@@ -148,7 +192,7 @@ func generateNodeKey(nodePrivKeyPath string) ([]byte, []byte, error) {
 		return nil, nil, err
 	}
 
-	if err := sgx.SealToFile(nodePrivKey.Serialize(), nodePrivKeyPath); err != nil {
+	if err := sgx.SealToFile(nodePrivKey.Serialize(), nodePrivKeyPath, sgx.SealedFileTypeNodeKey); err != nil {
 		return nil, nil, err
 	}
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/medibloc/panacea-doracle/client/flags"
 	"github.com/medibloc/panacea-doracle/config"
+	"github.com/medibloc/panacea-doracle/sgx"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/spf13/cobra"
@@ -57,6 +58,9 @@ func init() {
 		registerOracleCmd(),
 		getOracleKeyCmd(),
 		upgradeOracleCmd(),
+		lightClientCmd(),
+		migrateSealedCmd(),
+		enclaveInfoCmd(),
 	)
 }
 
@@ -76,5 +80,9 @@ func loadConfigFromHome(cmd *cobra.Command) (*config.Config, error) {
 		return nil, fmt.Errorf("failed to init logger: %w", err)
 	}
 
+	if err := sgx.InitEnclave(conf); err != nil {
+		return nil, fmt.Errorf("failed to init enclave: %w", err)
+	}
+
 	return conf, nil
 }
@@ -2,14 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"os/signal"
-	"syscall"
 
 	datadealevent "github.com/medibloc/panacea-doracle/event/datadeal"
 	oracleevent "github.com/medibloc/panacea-doracle/event/oracle"
+	"github.com/medibloc/panacea-doracle/server"
 	"github.com/medibloc/panacea-doracle/service"
-	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -39,13 +36,8 @@ func startCmd() *cobra.Command {
 				return fmt.Errorf("failed to start event subscription: %w", err)
 			}
 
-			sigChan := make(chan os.Signal, 1)
-
-			signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
-			<-sigChan
-			log.Infof("signal detected")
-
-			return nil
+			// run the HTTP server until a signal is detected
+			return server.Run(conf, svc)
 		},
 	}
 
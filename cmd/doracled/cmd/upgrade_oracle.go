@@ -8,13 +8,12 @@ import (
 	"fmt"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	oracletypes "github.com/medibloc/panacea-core/v2/x/oracle/types"
-	"github.com/medibloc/panacea-doracle/client/flags"
 	log "github.com/sirupsen/logrus"
 	"github.com/tendermint/tendermint/libs/os"
 	"io"
 
-	"github.com/edgelesssys/ego/enclave"
 	"github.com/medibloc/panacea-doracle/panacea"
+	"github.com/medibloc/panacea-doracle/sgx"
 	"github.com/spf13/cobra"
 )
 
@@ -36,7 +35,7 @@ func upgradeOracleCmd() *cobra.Command {
 			}
 
 			// get trusted block information
-			trustedBlockInfo, err := getTrustedBlockInfo(cmd)
+			trustedBlockInfo, err := getTrustedBlockInfo(cmd, conf)
 			if err != nil {
 				return fmt.Errorf("failed to get trusted block info: %w", err)
 			}
@@ -60,7 +59,10 @@ func upgradeOracleCmd() *cobra.Command {
 				return fmt.Errorf("failed to generate node key pair: %w", err)
 			}
 
-			report, _ := enclave.VerifyRemoteReport(nodePubKeyRemoteReport)
+			report, err := sgx.ParseRemoteReport(nodePubKeyRemoteReport)
+			if err != nil {
+				return fmt.Errorf("failed to parse remote report of node key: %w", err)
+			}
 			uniqueID := hex.EncodeToString(report.UniqueID)
 
 			nonce := make([]byte, 12)
@@ -107,10 +109,7 @@ func upgradeOracleCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().Int64(flags.FlagTrustedBlockHeight, 0, "Trusted block height")
-	cmd.Flags().String(flags.FlagTrustedBlockHash, "", "Trusted block hash")
-	_ = cmd.MarkFlagRequired(flags.FlagTrustedBlockHeight)
-	_ = cmd.MarkFlagRequired(flags.FlagTrustedBlockHash)
+	addTrustedBlockFlags(cmd)
 
 	return cmd
 }
@@ -1,41 +1,206 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/medibloc/panacea-doracle/client/flags"
+	"github.com/medibloc/panacea-doracle/config"
+	"github.com/medibloc/panacea-doracle/panacea"
 	"github.com/medibloc/panacea-doracle/sgx"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	tos "github.com/tendermint/tendermint/libs/os"
 )
 
+// reportVerdict is the result of verifying a remote report, which is printed as JSON.
+type reportVerdict struct {
+	Target          string   `json:"target"`
+	Verified        bool     `json:"verified"`
+	Error           string   `json:"error,omitempty"`
+	UniqueID        string   `json:"unique_id,omitempty"`
+	SignerID        string   `json:"signer_id,omitempty"`
+	ProductID       string   `json:"product_id,omitempty"`
+	SecurityVersion uint     `json:"security_version,omitempty"`
+	Debug           bool     `json:"debug,omitempty"`
+	TCBStatus       string   `json:"tcb_status,omitempty"`
+	TCBAction       string   `json:"tcb_action,omitempty"`
+	TCBAdvisoryIDs  []string `json:"tcb_advisory_ids,omitempty"`
+	Warnings        []string `json:"warnings,omitempty"`
+}
+
 func verifyReportCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "verify-report [report-file-path]",
 		Short: "Verify whether the report was properly generated in the SGX environment",
-		Args:  cobra.ExactArgs(1),
+		Long: `Verify whether the report was properly generated in the SGX environment.
+
+The report can be read from a file (e.g. oracle_pub_key.json), or from the chain through the light client:
+- with --oracle-address and --registration-unique-id, the node key report of the oracle registration is verified.
+- with --oracle-params, the oracle public key report in the oracle params is verified.
+The results are printed as JSON.
+
+By default, the report is verified by SGX, and it is trusted by the trust policy in the config if the app is initialized.
+Otherwise, it is trusted only if it was generated by the same binary as this.
+With --unique-id, --signer-id and --product-id, only the given enclave is trusted instead.
+With --collateral-dir, the report is verified in pure Go by the collateral in the directory, even without SGX.
+In that case, the trusted enclave must be given by --unique-id, --signer-id and --product-id.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// read oracle remote targetReport
-			pubKeyInfo, err := readOracleRemoteReport(args[0])
+			oracleAddress, err := cmd.Flags().GetString(flags.FlagOracleAddress)
+			if err != nil {
+				return err
+			}
+			registrationUniqueID, err := cmd.Flags().GetString(flags.FlagRegistrationUniqueID)
+			if err != nil {
+				return err
+			}
+			oracleParams, err := cmd.Flags().GetBool(flags.FlagOracleParams)
 			if err != nil {
-				log.Errorf("failed to read remote targetReport: %v", err)
 				return err
 			}
+			onChain := oracleAddress != "" || oracleParams
+			if len(args) == 0 && !onChain {
+				return errors.New("a report file, --oracle-address or --oracle-params is required")
+			}
+
+			// use the config if the app is initialized. otherwise, SGX is used.
+			homeDir, err := cmd.Flags().GetString(flags.FlagHome)
+			if err != nil {
+				return fmt.Errorf("failed to read a home flag: %w", err)
+			}
+			var conf *config.Config
+			if tos.FileExists(getConfigPath(homeDir)) {
+				conf, err = loadConfigFromHome(cmd)
+				if err != nil {
+					return err
+				}
+			}
 
-			if err := verifyPubKeyRemoteReport(*pubKeyInfo); err != nil {
-				log.Errorf("failed to verify the public key and its remote report: %v", err)
+			policy, err := trustPolicyForReport(cmd, conf)
+			if err != nil {
 				return err
 			}
 
+			var verdicts []reportVerdict
+			if len(args) == 1 {
+				verdicts = append(verdicts, verifyReportFile(policy, args[0]))
+			}
+
+			if onChain {
+				if conf == nil {
+					return errors.New("the app must be initialized to query the chain through the light client")
+				}
+				queryClient, err := panacea.LoadQueryClient(context.Background(), conf)
+				if err != nil {
+					return fmt.Errorf("failed to load query client: %w", err)
+				}
+				defer queryClient.Close()
+
+				if oracleAddress != "" {
+					verdicts = append(verdicts, verifyOracleRegistrationReport(queryClient, policy, oracleAddress, registrationUniqueID))
+				}
+				if oracleParams {
+					verdicts = append(verdicts, verifyOracleParamsReport(queryClient, policy))
+				}
+			}
+
+			verdictsBz, err := json.MarshalIndent(verdicts, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal verdicts: %w", err)
+			}
+			fmt.Println(string(verdictsBz))
+
+			for _, verdict := range verdicts {
+				if !verdict.Verified {
+					return fmt.Errorf("failed to verify the report of %s: %s", verdict.Target, verdict.Error)
+				}
+			}
+
 			log.Infof("report verification success")
 			return nil
 		},
 	}
+	cmd.Flags().String(flags.FlagCollateralDir, "", "directory of the collateral to verify the report without SGX")
+	cmd.Flags().String(flags.FlagUniqueID, "", "hex-encoded unique ID (MRENCLAVE) of the trusted enclave")
+	cmd.Flags().String(flags.FlagSignerID, "", "hex-encoded signer ID (MRSIGNER) of the trusted enclave")
+	cmd.Flags().String(flags.FlagProductID, "", "hex-encoded product ID of the trusted enclave")
+	cmd.Flags().String(flags.FlagOracleAddress, "", "address of the oracle registration to verify")
+	cmd.Flags().String(flags.FlagRegistrationUniqueID, "", "hex-encoded unique ID of the oracle registration to verify")
+	cmd.Flags().Bool(flags.FlagOracleParams, false, "verify the oracle public key report in the oracle params")
+	cmd.MarkFlagsRequiredTogether(flags.FlagUniqueID, flags.FlagSignerID, flags.FlagProductID)
+	cmd.MarkFlagsRequiredTogether(flags.FlagOracleAddress, flags.FlagRegistrationUniqueID)
+
 	return cmd
 }
 
+// trustPolicyForReport returns the trust policy of the enclave given by flags, if any.
+// Otherwise, it returns the trust policy in the config, or the policy which trusts only the same binary as this.
+func trustPolicyForReport(cmd *cobra.Command, conf *config.Config) (*sgx.TrustPolicy, error) {
+	collateralDir, err := cmd.Flags().GetString(flags.FlagCollateralDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.Flags().Changed(flags.FlagUniqueID) {
+		enclaveInfo, err := enclaveInfoFromFlags(cmd)
+		if err != nil {
+			return nil, err
+		}
+		policy := sgx.NewExactTrustPolicy(*enclaveInfo)
+
+		if collateralDir != "" {
+			collateral, err := sgx.LoadCollateral(collateralDir)
+			if err != nil {
+				return nil, err
+			}
+			policy = policy.WithVerifier(sgx.NewDCAPVerifier(collateral))
+		}
+		return policy, nil
+	}
+
+	if collateralDir != "" {
+		return nil, fmt.Errorf("--%s, --%s and --%s are required with --%s", flags.FlagUniqueID, flags.FlagSignerID, flags.FlagProductID, flags.FlagCollateralDir)
+	}
+
+	selfEnclaveInfo, err := sgx.GetSelfEnclaveInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to set self-enclave info: %w", err)
+	}
+	if conf == nil {
+		return sgx.NewExactTrustPolicy(*selfEnclaveInfo), nil
+	}
+
+	policy, err := sgx.NewTrustPolicy(conf.Enclave, selfEnclaveInfo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trust policy: %w", err)
+	}
+	return policy.WithDefaultUniqueIDs(selfEnclaveInfo.UniqueID), nil
+}
+
+func enclaveInfoFromFlags(cmd *cobra.Command) (*sgx.EnclaveInfo, error) {
+	ids := make(map[string][]byte)
+	for _, flag := range []string{flags.FlagUniqueID, flags.FlagSignerID, flags.FlagProductID} {
+		value, err := cmd.Flags().GetString(flag)
+		if err != nil {
+			return nil, err
+		}
+		id, err := hex.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", flag, err)
+		}
+		ids[flag] = id
+	}
+	return sgx.NewEnclaveInfo(ids[flags.FlagProductID], ids[flags.FlagSignerID], ids[flags.FlagUniqueID]), nil
+}
+
 func readOracleRemoteReport(filename string) (*OraclePubKeyInfo, error) {
 	file, err := os.ReadFile(filename)
 	if err != nil {
@@ -51,26 +216,94 @@ func readOracleRemoteReport(filename string) (*OraclePubKeyInfo, error) {
 	return &pubKeyInfo, nil
 }
 
-func verifyPubKeyRemoteReport(pubKeyInfo OraclePubKeyInfo) error {
+// verifyReportFile verifies the public key and its remote report in the file.
+func verifyReportFile(policy *sgx.TrustPolicy, filename string) reportVerdict {
+	target := fmt.Sprintf("file %s", filename)
+
+	pubKeyInfo, err := readOracleRemoteReport(filename)
+	if err != nil {
+		return failedVerdict(target, fmt.Errorf("failed to read remote report: %w", err))
+	}
+
 	pubKey, err := base64.StdEncoding.DecodeString(pubKeyInfo.PublicKeyBase64)
 	if err != nil {
-		return fmt.Errorf("failed to decode oracle public key: %w", err)
+		return failedVerdict(target, fmt.Errorf("failed to decode oracle public key: %w", err))
 	}
 
-	targetReport, err := base64.StdEncoding.DecodeString(pubKeyInfo.RemoteReportBase64)
+	report, err := base64.StdEncoding.DecodeString(pubKeyInfo.RemoteReportBase64)
 	if err != nil {
-		return fmt.Errorf("failed to decode oracle public key remote report: %w", err)
+		return failedVerdict(target, fmt.Errorf("failed to decode oracle public key remote report: %w", err))
 	}
 
-	selfEnclaveInfo, err := sgx.GetSelfEnclaveInfo()
+	return verifyReport(policy, target, report, pubKey, nil)
+}
+
+// verifyOracleRegistrationReport verifies that the node key report of the oracle registration on the chain
+// contains the hash of the node public key, and it was generated by the enclave of the registration.
+func verifyOracleRegistrationReport(queryClient *panacea.QueryClient, policy *sgx.TrustPolicy, oracleAddress, uniqueID string) reportVerdict {
+	target := fmt.Sprintf("oracle registration %s/%s", oracleAddress, uniqueID)
+
+	expectedUniqueID, err := hex.DecodeString(uniqueID)
 	if err != nil {
-		return fmt.Errorf("failed to set self-enclave info: %w", err)
+		return failedVerdict(target, fmt.Errorf("invalid unique ID of the oracle registration: %w", err))
 	}
 
-	// verify remote report
-	if err := sgx.VerifyRemoteReport(targetReport, pubKey, *selfEnclaveInfo); err != nil {
-		return fmt.Errorf("failed to verify report: %w", err)
+	oracleRegistration, err := queryClient.GetOracleRegistration(oracleAddress, uniqueID)
+	if err != nil {
+		return failedVerdict(target, fmt.Errorf("failed to get oracle registration: %w", err))
 	}
 
-	return nil
+	nodePubKeyHash := sha256.Sum256(oracleRegistration.NodePubKey)
+	return verifyReport(policy, target, oracleRegistration.NodePubKeyRemoteReport, nodePubKeyHash[:], expectedUniqueID)
+}
+
+// verifyOracleParamsReport verifies that the oracle public key report in the oracle params contains the oracle public key.
+func verifyOracleParamsReport(queryClient *panacea.QueryClient, policy *sgx.TrustPolicy) reportVerdict {
+	target := "oracle params"
+
+	oraclePubKey, err := queryClient.GetOracleParamsPublicKey()
+	if err != nil {
+		return failedVerdict(target, fmt.Errorf("failed to get oracle public key: %w", err))
+	}
+
+	report, err := queryClient.GetOracleParamsPubKeyRemoteReport()
+	if err != nil {
+		return failedVerdict(target, fmt.Errorf("failed to get oracle public key remote report: %w", err))
+	}
+
+	return verifyReport(policy, target, report, oraclePubKey.SerializeCompressed(), nil)
+}
+
+// verifyReport verifies the report by the policy. If expectedUniqueID is not nil, the report must be generated by the enclave.
+func verifyReport(policy *sgx.TrustPolicy, target string, report, expectedData, expectedUniqueID []byte) reportVerdict {
+	result, err := policy.Verify(report, expectedData)
+	if err == nil && expectedUniqueID != nil && !bytes.Equal(result.Report.UniqueID, expectedUniqueID) {
+		err = fmt.Errorf("the report was generated by another enclave. expected uniqueID(%X), got(%X)", expectedUniqueID, result.Report.UniqueID)
+	}
+
+	verdict := reportVerdict{Target: target, Verified: err == nil}
+	if err != nil {
+		verdict.Error = err.Error()
+	}
+	if result != nil {
+		log.WithFields(result.LogFields()).Infof("TCB status of the report of %s: %s", target, result.Report.TCBStatus)
+
+		verdict.UniqueID = hex.EncodeToString(result.Report.UniqueID)
+		verdict.SignerID = hex.EncodeToString(result.Report.SignerID)
+		verdict.ProductID = hex.EncodeToString(result.Report.ProductID)
+		verdict.SecurityVersion = result.Report.SecurityVersion
+		verdict.Debug = result.Report.Debug
+		verdict.TCBStatus = result.Report.TCBStatus.String()
+		verdict.TCBAction = result.TCBAction.String()
+		verdict.TCBAdvisoryIDs = result.TCBAdvisoryIDs
+		verdict.Warnings = result.Warnings
+	}
+	for _, warning := range verdict.Warnings {
+		log.Warnf("%s: %s", target, warning)
+	}
+	return verdict
+}
+
+func failedVerdict(target string, err error) reportVerdict {
+	return reportVerdict{Target: target, Error: err.Error()}
 }
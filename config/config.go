@@ -1,17 +1,50 @@
 package config
 
 import (
+	"encoding/hex"
+	"fmt"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	tmmath "github.com/tendermint/tendermint/libs/math"
+	"github.com/tendermint/tendermint/light"
+	tmdb "github.com/tendermint/tm-db"
 )
 
+const (
+	LightClientVerificationModeSkipping   = "skipping"
+	LightClientVerificationModeSequential = "sequential"
+
+	EnclaveModeSGX        = "sgx"
+	EnclaveModeSimulation = "simulation"
+
+	SealPolicyUnique  = "unique"
+	SealPolicyProduct = "product"
+
+	StorageBackendIpfs       = "ipfs"
+	StorageBackendFileSystem = "filesystem"
+	StorageBackendS3         = "s3"
+)
+
+// mainnetChainIDRegex matches chain IDs of the Panacea mainnet (e.g. panacea-3)
+var mainnetChainIDRegex = regexp.MustCompile(`^panacea-[0-9]+$`)
+
 type Config struct {
 	BaseConfig `mapstructure:",squash"`
 
 	Panacea PanaceaConfig `mapstructure:"panacea"`
 
 	Ipfs IpfsConfig `mapstructure:"ipfs"`
+
+	Storage StorageConfig `mapstructure:"storage"`
+
+	Content ContentConfig `mapstructure:"content"`
+
+	Enclave EnclaveConfig `mapstructure:"enclave"`
 }
 
 type BaseConfig struct {
@@ -24,6 +57,7 @@ type BaseConfig struct {
 	ListenAddr     string `mapstructure:"listen_addr"`
 	Subscriber     string `mapstructure:"subscriber"`
 	DataDir        string `mapstructure:"data_dir"`
+	DBBackend      string `mapstructure:"db_backend"`
 
 	OraclePrivKeyFile string `mapstructure:"oracle_priv_key_file"`
 	OraclePubKeyFile  string `mapstructure:"oracle_pub_key_file"`
@@ -39,10 +73,73 @@ type PanaceaConfig struct {
 	LightClientPrimaryAddr  string   `mapstructure:"light-client-primary-addr"`
 	LightClientWitnessAddrs []string `mapstructure:"light-client-witness-addrs"`
 	LightClientLogLevel     string   `mapstructure:"light-client-log-level"`
+
+	LightClientTrustingPeriod   time.Duration `mapstructure:"light-client-trusting-period"`
+	LightClientTrustLevel       string        `mapstructure:"light-client-trust-level"`
+	LightClientVerificationMode string        `mapstructure:"light-client-verification-mode"`
+	LightClientMaxClockDrift    time.Duration `mapstructure:"light-client-max-clock-drift"`
+	LightClientSubmitEvidence   bool          `mapstructure:"light-client-submit-evidence"`
+	LightClientRefreshInterval  time.Duration `mapstructure:"light-client-refresh-interval"`
 }
 
 type IpfsConfig struct {
 	IpfsNodeAddr string `mapstructure:"ipfs-node-addr"`
+
+	// The timeout of each request to the IPFS node, including reading the response.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+type StorageConfig struct {
+	// The storage of data uploaded by sellers and delivered to buyers: "ipfs", "filesystem" or "s3".
+	Backend string `mapstructure:"backend"`
+
+	FileSystemDir string `mapstructure:"filesystem-dir"`
+
+	S3Endpoint        string        `mapstructure:"s3-endpoint"`
+	S3Region          string        `mapstructure:"s3-region"`
+	S3Bucket          string        `mapstructure:"s3-bucket"`
+	S3AccessKeyID     string        `mapstructure:"s3-access-key-id"`
+	S3SecretAccessKey string        `mapstructure:"s3-secret-access-key"`
+	S3PathStyle       bool          `mapstructure:"s3-path-style"`
+	S3Timeout         time.Duration `mapstructure:"s3-timeout"`
+}
+
+type ContentConfig struct {
+	// The maximum size of content fetched from each source, in bytes.
+	IpfsMaxSize       int64 `mapstructure:"ipfs-max-size"`
+	HTTPSMaxSize      int64 `mapstructure:"https-max-size"`
+	S3MaxSize         int64 `mapstructure:"s3-max-size"`
+	FileSystemMaxSize int64 `mapstructure:"filesystem-max-size"`
+
+	HTTPSTimeout time.Duration `mapstructure:"https-timeout"`
+	// If empty, content can be fetched from any host with a public IP address.
+	HTTPSAllowedHosts []string `mapstructure:"https-allowed-hosts"`
+	// If empty, content cannot be fetched from 's3://'.
+	S3AllowedBuckets []string `mapstructure:"s3-allowed-buckets"`
+}
+
+type EnclaveConfig struct {
+	Mode string `mapstructure:"mode"`
+
+	// The key to seal files and the light client data key: "unique" or "product".
+	SealPolicy string `mapstructure:"seal-policy"`
+
+	// These are used only by the simulated enclave, as the enclave information in its mock reports.
+	SimulationUniqueID  string `mapstructure:"simulation-unique-id"`
+	SimulationSignerID  string `mapstructure:"simulation-signer-id"`
+	SimulationProductID string `mapstructure:"simulation-product-id"`
+
+	// The trust policy to verify remote reports of other oracles.
+	// If signer IDs or product IDs are empty, only the ones of this oracle are trusted.
+	// If unique IDs are empty, the unique ID of this oracle is trusted for registrations,
+	// and the unique ID being upgraded to is trusted for upgrades.
+	TrustedSignerIDs   []string `mapstructure:"trusted-signer-ids"`
+	TrustedProductIDs  []string `mapstructure:"trusted-product-ids"`
+	TrustedUniqueIDs   []string `mapstructure:"trusted-unique-ids"`
+	MinSecurityVersion uint     `mapstructure:"min-security-version"`
+	// Advisory IDs of TCB statuses are available only when reports are verified by the DCAP verifier with the collateral.
+	AllowedTCBStatuses []string `mapstructure:"allowed-tcb-statuses"`
+	WarnTCBStatuses    []string `mapstructure:"warn-tcb-statuses"`
 }
 
 func DefaultConfig() *Config {
@@ -56,6 +153,7 @@ func DefaultConfig() *Config {
 			OracleAccIndex: 0,
 			ListenAddr:     "127.0.0.1:8080",
 			DataDir:        "data",
+			DBBackend:      string(tmdb.GoLevelDBBackend),
 
 			OraclePrivKeyFile: "oracle_priv_key.sealed",
 			OraclePubKeyFile:  "oracle_pub_key.json",
@@ -64,15 +162,55 @@ func DefaultConfig() *Config {
 		Panacea: PanaceaConfig{
 			GRPCAddr:                "http://127.0.0.1:9090",
 			RPCAddr:                 "tcp://127.0.0.1:26657",
-			ChainID:                 "panacea-3",
+			ChainID:                 DefaultChainID,
 			DefaultGasLimit:         400000,
 			DefaultFeeAmount:        "2000000umed",
 			LightClientPrimaryAddr:  "tcp://127.0.0.1:26657",
 			LightClientWitnessAddrs: []string{"tcp://127.0.0.1:26657"},
 			LightClientLogLevel:     "error",
+
+			LightClientTrustingPeriod:   2 * 7 * 24 * time.Hour,
+			LightClientTrustLevel:       "1/3",
+			LightClientVerificationMode: LightClientVerificationModeSkipping,
+			LightClientMaxClockDrift:    10 * time.Second,
+			LightClientSubmitEvidence:   true,
+			LightClientRefreshInterval:  1 * time.Minute,
 		},
 		Ipfs: IpfsConfig{
 			IpfsNodeAddr: "127.0.0.1:5001",
+			Timeout:      1 * time.Minute,
+		},
+		Storage: StorageConfig{
+			Backend:       StorageBackendIpfs,
+			FileSystemDir: "storage",
+			S3Endpoint:    "",
+			S3Region:      "us-east-1",
+			S3Bucket:      "",
+			S3PathStyle:   true,
+			S3Timeout:     1 * time.Minute,
+		},
+		Content: ContentConfig{
+			IpfsMaxSize:       100 << 20,
+			HTTPSMaxSize:      100 << 20,
+			S3MaxSize:         100 << 20,
+			FileSystemMaxSize: 100 << 20,
+			HTTPSTimeout:      1 * time.Minute,
+			HTTPSAllowedHosts: []string{},
+			S3AllowedBuckets:  []string{},
+		},
+		Enclave: EnclaveConfig{
+			Mode:                DefaultEnclaveMode,
+			SealPolicy:          SealPolicyUnique,
+			SimulationUniqueID:  "0000000000000000000000000000000000000000000000000000000000000001",
+			SimulationSignerID:  "0000000000000000000000000000000000000000000000000000000000000002",
+			SimulationProductID: "01000000000000000000000000000000",
+
+			TrustedSignerIDs:   []string{},
+			TrustedProductIDs:  []string{},
+			TrustedUniqueIDs:   []string{},
+			MinSecurityVersion: 1,
+			AllowedTCBStatuses: []string{"UpToDate"},
+			WarnTCBStatuses:    []string{},
 		},
 	}
 }
@@ -83,9 +221,118 @@ func (c *Config) validate() error {
 		return err
 	}
 
+	if dbBackends := registeredDBBackends(); !isRegisteredDBBackend(dbBackends, c.DBBackend) {
+		return fmt.Errorf("invalid db_backend: %s. the backends built into this binary are %s", c.DBBackend, strings.Join(dbBackends, ", "))
+	}
+
+	switch c.Enclave.Mode {
+	case EnclaveModeSGX:
+	case EnclaveModeSimulation:
+		if IsMainnetChainID(c.Panacea.ChainID) {
+			return fmt.Errorf("the simulated enclave cannot be used for the mainnet chain: %s", c.Panacea.ChainID)
+		}
+		for name, id := range map[string]string{
+			"simulation-unique-id":  c.Enclave.SimulationUniqueID,
+			"simulation-signer-id":  c.Enclave.SimulationSignerID,
+			"simulation-product-id": c.Enclave.SimulationProductID,
+		} {
+			if _, err := hex.DecodeString(id); err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+		}
+	default:
+		return fmt.Errorf("invalid enclave mode: %s", c.Enclave.Mode)
+	}
+
+	switch c.Enclave.SealPolicy {
+	case SealPolicyUnique, SealPolicyProduct:
+	default:
+		return fmt.Errorf("invalid seal policy: %s", c.Enclave.SealPolicy)
+	}
+
+	for name, ids := range map[string][]string{
+		"trusted-signer-ids":  c.Enclave.TrustedSignerIDs,
+		"trusted-product-ids": c.Enclave.TrustedProductIDs,
+		"trusted-unique-ids":  c.Enclave.TrustedUniqueIDs,
+	} {
+		for _, id := range ids {
+			if _, err := hex.DecodeString(id); err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+		}
+	}
+	if len(c.Enclave.AllowedTCBStatuses) == 0 {
+		return fmt.Errorf("allowed-tcb-statuses must not be empty")
+	}
+
+	switch c.Storage.Backend {
+	case StorageBackendIpfs, StorageBackendFileSystem:
+	case StorageBackendS3:
+		if c.Storage.S3Endpoint == "" || c.Storage.S3Region == "" || c.Storage.S3Bucket == "" {
+			return fmt.Errorf("s3-endpoint, s3-region and s3-bucket are required for the s3 storage")
+		}
+		if c.Storage.S3Timeout <= 0 {
+			return fmt.Errorf("s3-timeout must be positive")
+		}
+	default:
+		return fmt.Errorf("invalid storage backend: %s", c.Storage.Backend)
+	}
+
+	if c.Ipfs.Timeout <= 0 {
+		return fmt.Errorf("ipfs timeout must be positive")
+	}
+
+	for _, maxSize := range []struct {
+		name string
+		size int64
+	}{
+		{"ipfs-max-size", c.Content.IpfsMaxSize},
+		{"https-max-size", c.Content.HTTPSMaxSize},
+		{"s3-max-size", c.Content.S3MaxSize},
+		{"filesystem-max-size", c.Content.FileSystemMaxSize},
+	} {
+		if maxSize.size <= 0 {
+			return fmt.Errorf("%s must be positive", maxSize.name)
+		}
+	}
+	if c.Content.HTTPSTimeout <= 0 {
+		return fmt.Errorf("https-timeout must be positive")
+	}
+
+	if c.Panacea.LightClientTrustingPeriod <= 0 {
+		return fmt.Errorf("light-client-trusting-period must be positive")
+	}
+
+	trustLevel, err := tmmath.ParseFraction(c.Panacea.LightClientTrustLevel)
+	if err != nil {
+		return fmt.Errorf("invalid light-client-trust-level: %w", err)
+	}
+	if err := light.ValidateTrustLevel(trustLevel); err != nil {
+		return fmt.Errorf("invalid light-client-trust-level: %w", err)
+	}
+
+	switch c.Panacea.LightClientVerificationMode {
+	case LightClientVerificationModeSkipping, LightClientVerificationModeSequential:
+	default:
+		return fmt.Errorf("invalid light-client-verification-mode: %s", c.Panacea.LightClientVerificationMode)
+	}
+
+	if c.Panacea.LightClientMaxClockDrift < 0 {
+		return fmt.Errorf("light-client-max-clock-drift must not be negative")
+	}
+
+	if c.Panacea.LightClientRefreshInterval <= 0 {
+		return fmt.Errorf("light-client-refresh-interval must be positive")
+	}
+
 	return nil
 }
 
+// IsMainnetChainID returns true if the chain ID is of the Panacea mainnet.
+func IsMainnetChainID(chainID string) bool {
+	return mainnetChainIDRegex.MatchString(chainID)
+}
+
 func (c *Config) SetHomeDir(dir string) {
 	c.homeDir = dir
 }
@@ -94,6 +341,10 @@ func (c *Config) AbsDataDirPath() string {
 	return rootify(c.DataDir, c.homeDir)
 }
 
+func (c *Config) AbsStorageFileSystemDir() string {
+	return rootify(c.Storage.FileSystemDir, c.homeDir)
+}
+
 func (c *Config) AbsOraclePrivKeyPath() string {
 	return rootify(c.OraclePrivKeyFile, c.homeDir)
 }
@@ -112,3 +363,32 @@ func rootify(path, root string) string {
 	}
 	return filepath.Join(root, path)
 }
+
+// registeredDBBackends returns the tm-db backends registered in this binary.
+// Backends other than goleveldb and memdb are registered only if the binary is built with their build tags.
+// pebbledb is never registered, because tm-db v0.6, which Tendermint v0.34 depends on, has no pebble backend.
+// tm-db doesn't export its registry, but NewDB lists the registered backends in the error for an unknown backend
+// without creating any db.
+func registeredDBBackends() []string {
+	_, err := tmdb.NewDB("", "", "")
+	if err == nil {
+		return nil
+	}
+
+	_, list, found := strings.Cut(err.Error(), "expected one of ")
+	if !found || list == "" {
+		return nil
+	}
+	backends := strings.Split(list, ",")
+	sort.Strings(backends)
+	return backends
+}
+
+func isRegisteredDBBackend(backends []string, backend string) bool {
+	for _, b := range backends {
+		if b == backend {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,11 @@
+//go:build sgx_simulation
+
+package config
+
+// DefaultEnclaveMode is the enclave mode used by default.
+// The binary built with the sgx_simulation build tag uses the simulated enclave by default.
+const DefaultEnclaveMode = EnclaveModeSimulation
+
+// DefaultChainID is the chain ID used by default.
+// The simulated enclave cannot be used for the mainnet, so the binary built with the sgx_simulation build tag uses a testing chain ID by default.
+const DefaultChainID = "testing"
@@ -26,6 +26,14 @@ oracle-acc-index = "{{ .BaseConfig.OracleAccIndex }}"
 listen_addr = "{{ .BaseConfig.ListenAddr }}"
 data_dir = "{{ .BaseConfig.DataDir }}"
 
+# The database backend of the light client store: goleveldb, cleveldb, memdb, boltdb, rocksdb or badgerdb.
+# All values are sealed regardless of the backend.
+# Backends other than goleveldb and memdb are available only if the binary is built with their build tags (e.g. -tags badgerdb),
+# and the config is rejected if the backend is not built into the binary.
+# pebbledb is not supported, because tm-db v0.6 used by Tendermint v0.34 has no pebble backend.
+# Note that memdb doesn't persist the light client store.
+db_backend = "{{ .BaseConfig.DBBackend }}"
+
 oracle_priv_key_file = "{{ .BaseConfig.OraclePrivKeyFile }}"
 oracle_pub_key_file = "{{ .BaseConfig.OraclePubKeyFile }}"
 node_priv_key_file = "{{ .BaseConfig.NodePrivKeyFile }}"
@@ -54,6 +62,32 @@ light-client-witness-addrs= "{{ StringsJoin .Panacea.LightClientWitnessAddrs ","
 
 light-client-log-level = "{{ .Panacea.LightClientLogLevel }}"
 
+# The period during which the light client trusts a verified block.
+# It must be shorter than the unbonding time of the chain.
+
+light-client-trusting-period = "{{ .Panacea.LightClientTrustingPeriod }}"
+
+# The fraction of the old validator set that must sign a new header in the skipping verification (e.g. "1/3")
+
+light-client-trust-level = "{{ .Panacea.LightClientTrustLevel }}"
+
+# The verification mode of light client: "skipping" or "sequential"
+
+light-client-verification-mode = "{{ .Panacea.LightClientVerificationMode }}"
+
+# How much a new header's time can drift into the future relative to the local time
+
+light-client-max-clock-drift = "{{ .Panacea.LightClientMaxClockDrift }}"
+
+# Whether to submit the evidence of light client attack to the chain.
+# The evidence is always written to the data directory regardless of this option.
+
+light-client-submit-evidence = "{{ .Panacea.LightClientSubmitEvidence }}"
+
+# How often the light client checks whether the last trusted block needs to be updated
+
+light-client-refresh-interval = "{{ .Panacea.LightClientRefreshInterval }}"
+
 ###############################################################################
 ###                         Ipfs Configuration                           ###
 ###############################################################################
@@ -61,6 +95,123 @@ light-client-log-level = "{{ .Panacea.LightClientLogLevel }}"
 [ipfs]
 
 ipfs-node-addr = "{{ .Ipfs.IpfsNodeAddr }}"
+
+# The timeout of each request to the IPFS node, including reading the response.
+# The maximum size of data fetched from IPFS is 'ipfs-max-size' in the [content] section.
+
+timeout = "{{ .Ipfs.Timeout }}"
+
+###############################################################################
+###                        Storage Configuration                            ###
+###############################################################################
+
+[storage]
+
+# The storage of data uploaded by sellers and delivered to buyers: "ipfs", "filesystem" or "s3".
+# With "filesystem" or "s3", sellers upload data to 'deals/<deal-id>/<verifiable-cid>',
+# and data delivered to buyers is stored as 'deals/<deal-id>/<sha256-of-data>'.
+
+backend = "{{ .Storage.Backend }}"
+
+# The directory of the "filesystem" storage
+
+filesystem-dir = "{{ .Storage.FileSystemDir }}"
+
+# The S3-compatible storage (e.g. AWS S3, MinIO) of the "s3" storage.
+# With 's3-path-style', objects are accessed by '<endpoint>/<bucket>/<key>' instead of '<bucket>.<endpoint host>/<key>'.
+
+s3-endpoint = "{{ .Storage.S3Endpoint }}"
+s3-region = "{{ .Storage.S3Region }}"
+s3-bucket = "{{ .Storage.S3Bucket }}"
+s3-access-key-id = "{{ .Storage.S3AccessKeyID }}"
+s3-secret-access-key = "{{ .Storage.S3SecretAccessKey }}"
+s3-path-style = "{{ .Storage.S3PathStyle }}"
+s3-timeout = "{{ .Storage.S3Timeout }}"
+
+###############################################################################
+###                        Content Configuration                            ###
+###############################################################################
+
+[content]
+
+# The verifiable CID of data can be a URI of the source: 'ipfs://<cid>', 'https://<host>/<path>' or 's3://<bucket>/<key>'.
+# A bare CID is fetched from the storage above.
+# Objects in 's3://' are fetched with the endpoint and credentials of the S3-compatible storage above,
+# even if the storage backend is not "s3".
+# If a URI has a fragment of '#sha256=<hex-encoded-hash>', the content must match the hash.
+
+# The maximum size of content fetched from each source, in bytes
+
+ipfs-max-size = "{{ .Content.IpfsMaxSize }}"
+https-max-size = "{{ .Content.HTTPSMaxSize }}"
+s3-max-size = "{{ .Content.S3MaxSize }}"
+filesystem-max-size = "{{ .Content.FileSystemMaxSize }}"
+
+# The timeout of fetching content from 'https://'
+
+https-timeout = "{{ .Content.HTTPSTimeout }}"
+
+# Hosts (comma-separated) which content can be fetched from by 'https://'.
+# If empty, any host is allowed. In any case, hosts with loopback, private or link-local IP addresses are rejected.
+
+https-allowed-hosts = "{{ StringsJoin .Content.HTTPSAllowedHosts "," }}"
+
+# Buckets (comma-separated) which content can be fetched from by 's3://'. If empty, 's3://' is not allowed.
+# Sellers can read any object in these buckets with the credentials of the oracle.
+# If the bucket of the "s3" storage backend is listed, only objects of the deal ('deals/<deal-id>/') can be read from it.
+
+s3-allowed-buckets = "{{ StringsJoin .Content.S3AllowedBuckets "," }}"
+
+###############################################################################
+###                        Enclave Configuration                            ###
+###############################################################################
+
+[enclave]
+
+# The enclave which runs the oracle: "sgx" or "simulation".
+# The simulated enclave doesn't protect any data, and its reports are self-signed.
+# It is only for development and testing without SGX, so it cannot be used for the mainnet chain.
+
+mode = "{{ .Enclave.Mode }}"
+
+# The key to seal the oracle key, the node key and the data key of the light client DB: "unique" or "product".
+# With "unique", sealed files can be unsealed only by the same binary, so they must be recovered after every upgrade.
+# With "product", sealed files can be unsealed by any binary signed by the same signer with the same product ID,
+# and with the same or a higher security version. A binary with a lower security version cannot unseal files
+# sealed by a binary with a higher one, so the files must be recovered if the binary is rolled back.
+# To change the policy of existing sealed files, run 'doracled migrate-sealed'.
+
+seal-policy = "{{ .Enclave.SealPolicy }}"
+
+# The enclave information included in reports of the simulated enclave (hex-encoded)
+
+simulation-unique-id = "{{ .Enclave.SimulationUniqueID }}"
+simulation-signer-id = "{{ .Enclave.SimulationSignerID }}"
+simulation-product-id = "{{ .Enclave.SimulationProductID }}"
+
+# The trust policy to verify remote reports of other oracles (comma-separated, hex-encoded IDs).
+# If signer IDs or product IDs are empty, only the ones of this oracle are trusted.
+# If unique IDs are empty, the unique ID of this oracle is trusted for registrations,
+# and the unique ID being upgraded to is trusted for upgrades.
+
+trusted-signer-ids = "{{ StringsJoin .Enclave.TrustedSignerIDs "," }}"
+trusted-product-ids = "{{ StringsJoin .Enclave.TrustedProductIDs "," }}"
+trusted-unique-ids = "{{ StringsJoin .Enclave.TrustedUniqueIDs "," }}"
+
+# The minimum security version (ISVSVN) of trusted enclaves
+
+min-security-version = "{{ .Enclave.MinSecurityVersion }}"
+
+# The TCB statuses of trusted SGX platforms (comma-separated).
+# e.g. UpToDate, SWHardeningNeeded, ConfigurationNeeded, ConfigurationAndSWHardeningNeeded, OutOfDate
+# Reports with 'allowed-tcb-statuses' are accepted, and reports with 'warn-tcb-statuses' are accepted with a warning.
+# Reports with other TCB statuses are rejected.
+# The IDs of Intel security advisories (e.g. INTEL-SA-00334) affecting the TCB are reported only if reports are verified
+# with the collateral ('doracled verify-report --collateral-dir'). The oracle verifies reports by the quote provider
+# library of the platform, which doesn't provide them, so its warnings don't include advisory IDs.
+
+allowed-tcb-statuses = "{{ StringsJoin .Enclave.AllowedTCBStatuses "," }}"
+warn-tcb-statuses = "{{ StringsJoin .Enclave.WarnTCBStatuses "," }}"
 `
 
 var configTemplate *template.Template
@@ -86,15 +237,27 @@ func WriteConfigTOML(path string, config *Config) error {
 	return os.WriteFile(path, buffer.Bytes(), 0600)
 }
 
+// ReadConfigTOML reads the config file on top of the default config,
+// so that keys which are not in the file (e.g. added after the file was written) have their default values.
 func ReadConfigTOML(path string) (*Config, error) {
 	fileExt := filepath.Ext(path)
 
+	var defaultConfig bytes.Buffer
+	if err := configTemplate.Execute(&defaultConfig, DefaultConfig()); err != nil {
+		return nil, fmt.Errorf("failed to populate default config template: %w", err)
+	}
+
 	v := viper.New()
+	v.SetConfigType("toml")
+	if err := v.ReadConfig(&defaultConfig); err != nil {
+		return nil, fmt.Errorf("failed to read default config: %w", err)
+	}
+
 	v.AddConfigPath(filepath.Dir(path))
 	v.SetConfigName(strings.TrimSuffix(filepath.Base(path), fileExt))
 	v.SetConfigType(fileExt[1:]) // excluding the dot
 
-	if err := v.ReadInConfig(); err != nil {
+	if err := v.MergeInConfig(); err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
@@ -2,6 +2,7 @@ package config_test
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/medibloc/panacea-doracle/config"
@@ -19,3 +20,82 @@ func TestWriteAndReadConfigTOML(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, config.DefaultConfig(), conf)
 }
+
+func TestReadConfigTOMLInvalid(t *testing.T) {
+	testCases := []struct {
+		name        string
+		modify      func(conf *config.Config)
+		expectedErr string
+	}{
+		{
+			"light client trust level",
+			func(conf *config.Config) { conf.Panacea.LightClientTrustLevel = "1/4" },
+			"invalid light-client-trust-level",
+		},
+		{
+			"unknown db backend",
+			func(conf *config.Config) { conf.DBBackend = "unknown" },
+			"invalid db_backend: unknown",
+		},
+		{
+			"pebble db backend",
+			func(conf *config.Config) { conf.DBBackend = "pebbledb" },
+			"invalid db_backend: pebbledb",
+		},
+		{
+			// cleveldb is registered in tm-db only with the cleveldb build tag.
+			"db backend not built into the binary",
+			func(conf *config.Config) { conf.DBBackend = "cleveldb" },
+			"invalid db_backend: cleveldb",
+		},
+		{
+			"simulated enclave on mainnet",
+			func(conf *config.Config) {
+				conf.Enclave.Mode = config.EnclaveModeSimulation
+				conf.Panacea.ChainID = "panacea-3"
+			},
+			"cannot be used for the mainnet chain",
+		},
+		{
+			"seal policy",
+			func(conf *config.Config) { conf.Enclave.SealPolicy = "signer" },
+			"invalid seal policy",
+		},
+		{
+			"s3 storage without endpoint",
+			func(conf *config.Config) { conf.Storage.Backend = config.StorageBackendS3 },
+			"s3-endpoint, s3-region and s3-bucket are required",
+		},
+		{
+			"content max size",
+			func(conf *config.Config) { conf.Content.HTTPSMaxSize = 0 },
+			"https-max-size must be positive",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+
+			conf := config.DefaultConfig()
+			tc.modify(conf)
+
+			err := config.WriteConfigTOML(path, conf)
+			require.NoError(t, err)
+
+			_, err = config.ReadConfigTOML(path)
+			require.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
+
+// TestReadConfigTOMLWithMissingKeys reads the config.toml written by the first release,
+// which doesn't have keys added later. The missing keys must have default values.
+func TestReadConfigTOMLWithMissingKeys(t *testing.T) {
+	conf, err := config.ReadConfigTOML("./testdata/config_v0.toml")
+	require.NoError(t, err)
+
+	expected := config.DefaultConfig()
+	expected.Panacea.ChainID = "testing"
+	require.EqualValues(t, expected, conf)
+}
@@ -0,0 +1,302 @@
+package content
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/medibloc/panacea-doracle/config"
+	"github.com/medibloc/panacea-doracle/ipfs"
+	"github.com/medibloc/panacea-doracle/store"
+)
+
+const (
+	SchemeIpfs  = "ipfs"
+	SchemeHTTPS = "https"
+	SchemeS3    = "s3"
+
+	// integrityFragmentPrefix is the prefix of the URI fragment which contains the hex-encoded SHA256 hash of the content.
+	integrityFragmentPrefix = "sha256="
+)
+
+var (
+	// ErrTooLarge is returned if the content is larger than the maximum size of its source.
+	ErrTooLarge = errors.New("content too large")
+	// ErrIntegrity is returned if the content doesn't match the hash in the URI.
+	ErrIntegrity = errors.New("content integrity check failed")
+	// ErrNotAllowed is returned if the source of the content is not allowed by the config.
+	ErrNotAllowed = errors.New("source not allowed")
+)
+
+// Resolver fetches content by a URI (ipfs://, https://, s3://) or a bare CID.
+type Resolver struct {
+	conf       config.ContentConfig
+	ipfs       *ipfs.Ipfs
+	storage    store.Storage
+	s3         *store.S3Storage
+	httpClient *http.Client
+
+	// storageBucket is the bucket of the "s3" storage backend, which contains data of all deals.
+	storageBucket string
+}
+
+// NewResolver creates a Resolver. If storage is nil, bare CIDs are fetched from IPFS.
+// The S3-compatible storage in the config is used for s3:// URIs, if its endpoint is set.
+// The URIs are given by sellers, so only the allowed buckets and hosts in the config can be accessed.
+func NewResolver(conf *config.Config, ipfs *ipfs.Ipfs, storage store.Storage) (*Resolver, error) {
+	var s3 *store.S3Storage
+	if conf.Storage.S3Endpoint != "" {
+		var err error
+		s3, err = store.NewS3Storage(conf.Storage)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var storageBucket string
+	if conf.Storage.Backend == config.StorageBackendS3 {
+		storageBucket = conf.Storage.S3Bucket
+	}
+
+	r := &Resolver{
+		conf:          conf.Content,
+		ipfs:          ipfs,
+		storage:       storage,
+		s3:            s3,
+		storageBucket: storageBucket,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would connect to hosts which are not checked by the dialer.
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   rejectNonPublicAddr,
+	}).DialContext
+
+	r.httpClient = &http.Client{
+		Timeout:   conf.Content.HTTPSTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.URL.Scheme != SchemeHTTPS {
+				return fmt.Errorf("redirect to %s is not allowed", req.URL.Scheme)
+			}
+			if len(via) >= 10 {
+				return errors.New("too many redirects")
+			}
+			return r.checkHTTPSHost(req.URL)
+		},
+	}
+	return r, nil
+}
+
+// Get returns the content of the URI.
+// A bare CID is fetched from the storage under the default path, or from IPFS if no storage is set.
+func (r *Resolver) Get(ctx context.Context, uri, defaultPath string) ([]byte, error) {
+	if !strings.Contains(uri, "://") {
+		return r.getBare(ctx, uri, defaultPath)
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URI: %w", err)
+	}
+
+	expectedHash, err := integrityHash(u.Fragment)
+	if err != nil {
+		return nil, err
+	}
+	u.Fragment = ""
+
+	var data []byte
+	switch u.Scheme {
+	case SchemeIpfs:
+		data, err = r.getIpfs(ctx, u.Host+u.Path)
+	case SchemeHTTPS:
+		data, err = r.getHTTPS(ctx, u.String())
+	case SchemeS3:
+		data, err = r.getS3(ctx, u.Host, strings.TrimPrefix(u.Path, "/"), defaultPath)
+	default:
+		return nil, fmt.Errorf("unsupported URI scheme: %s", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if expectedHash != nil {
+		if hash := sha256.Sum256(data); !bytes.Equal(hash[:], expectedHash) {
+			return nil, fmt.Errorf("%w: expected(%X), got(%X)", ErrIntegrity, expectedHash, hash[:])
+		}
+	}
+	return data, nil
+}
+
+func (r *Resolver) getBare(ctx context.Context, cid, defaultPath string) ([]byte, error) {
+	if r.storage == nil {
+		return r.getIpfs(ctx, cid)
+	}
+
+	reader, err := r.storage.Download(ctx, defaultPath, cid)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	maxSize := r.conf.FileSystemMaxSize
+	if _, ok := r.storage.(*store.S3Storage); ok {
+		maxSize = r.conf.S3MaxSize
+	}
+	return readAll(reader, maxSize)
+}
+
+// getIpfs gets the data from IPFS. The IPFS client checks the size of the data with 'ipfs-max-size'.
+func (r *Resolver) getIpfs(ctx context.Context, cid string) ([]byte, error) {
+	if cid == "" {
+		return nil, errors.New("empty CID")
+	}
+
+	data, err := r.ipfs.Get(ctx, cid)
+	if errors.Is(err, ipfs.ErrTooLarge) {
+		return nil, fmt.Errorf("%w: %v", ErrTooLarge, err)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get %s from IPFS: %w", cid, err)
+	}
+	return data, nil
+}
+
+func (r *Resolver) getHTTPS(ctx context.Context, uri string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := r.checkHTTPSHost(req.URL); err != nil {
+		return nil, err
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", uri, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get %s: status %d", uri, resp.StatusCode)
+	}
+	if resp.ContentLength > r.conf.HTTPSMaxSize {
+		return nil, fmt.Errorf("%w: %d bytes from %s, limit %d", ErrTooLarge, resp.ContentLength, uri, r.conf.HTTPSMaxSize)
+	}
+
+	return readAll(resp.Body, r.conf.HTTPSMaxSize)
+}
+
+// checkHTTPSHost checks that the host is in 'https-allowed-hosts', if it is set.
+// The IP addresses of the host are checked when connecting.
+func (r *Resolver) checkHTTPSHost(u *url.URL) error {
+	if len(r.conf.HTTPSAllowedHosts) == 0 {
+		return nil
+	}
+	for _, host := range r.conf.HTTPSAllowedHosts {
+		if strings.EqualFold(host, u.Hostname()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: host %s is not in https-allowed-hosts", ErrNotAllowed, u.Hostname())
+}
+
+// getS3 gets the object from one of 's3-allowed-buckets'.
+// In the bucket of the storage backend, only objects under the path of the deal can be read,
+// so that sellers can't read data of other deals.
+func (r *Resolver) getS3(ctx context.Context, bucket, key, dealPath string) ([]byte, error) {
+	if r.s3 == nil {
+		return nil, errors.New("s3-endpoint is not set in the config")
+	}
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("invalid s3 URI: s3://%s/%s", bucket, key)
+	}
+	if !containsString(r.conf.S3AllowedBuckets, bucket) {
+		return nil, fmt.Errorf("%w: bucket %s is not in s3-allowed-buckets", ErrNotAllowed, bucket)
+	}
+	if bucket == r.storageBucket && !strings.HasPrefix(path.Clean("/"+key), path.Clean("/"+dealPath)+"/") {
+		return nil, fmt.Errorf("%w: s3://%s/%s is not under %s", ErrNotAllowed, bucket, key, dealPath)
+	}
+
+	dir, name := path.Split(key)
+	reader, err := r.s3.WithBucket(bucket).Download(ctx, dir, name)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return readAll(reader, r.conf.S3MaxSize)
+}
+
+// rejectNonPublicAddr is the control of the dialer for https://, which rejects connections to addresses which are not public,
+// so that sellers can't make the oracle access internal services. It is checked after DNS resolution.
+func rejectNonPublicAddr(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: address %s is not public", ErrNotAllowed, host)
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which is not covered by net.IP.IsPrivate.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// readAll reads all data, but stops reading as soon as the data exceeds the maximum size.
+func readAll(reader io.Reader, maxSize int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w: limit %d bytes", ErrTooLarge, maxSize)
+	}
+	return data, nil
+}
+
+func integrityHash(fragment string) ([]byte, error) {
+	if fragment == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(fragment, integrityFragmentPrefix) {
+		return nil, fmt.Errorf("unsupported URI fragment: %s", fragment)
+	}
+
+	hash, err := hex.DecodeString(strings.TrimPrefix(fragment, integrityFragmentPrefix))
+	if err != nil || len(hash) != sha256.Size {
+		return nil, fmt.Errorf("invalid SHA256 hash in URI fragment: %s", fragment)
+	}
+	return hash, nil
+}
@@ -0,0 +1,165 @@
+package content
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/medibloc/panacea-doracle/config"
+	"github.com/medibloc/panacea-doracle/ipfs"
+	"github.com/medibloc/panacea-doracle/store"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestResolver(t *testing.T, storage store.Storage, server *httptest.Server) *Resolver {
+	conf := config.DefaultConfig()
+	conf.Content.HTTPSMaxSize = 10
+	conf.Content.FileSystemMaxSize = 10
+
+	r, err := NewResolver(conf, ipfs.NewIpfs(conf), storage)
+	require.NoError(t, err)
+	if server != nil {
+		r.httpClient.Transport = server.Client().Transport
+	}
+	return r
+}
+
+func TestGetHTTPS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/data":
+			_, _ = w.Write([]byte("hello"))
+		case "/large":
+			_, _ = w.Write([]byte("hello world"))
+		case "/redirect":
+			http.Redirect(w, req, "http://example.com/data", http.StatusFound)
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer server.Close()
+
+	r := newTestResolver(t, nil, server)
+	ctx := context.Background()
+
+	data, err := r.Get(ctx, server.URL+"/data", "")
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), data)
+
+	hash := sha256.Sum256([]byte("hello"))
+	data, err = r.Get(ctx, server.URL+"/data#sha256="+hex.EncodeToString(hash[:]), "")
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), data)
+
+	hash = sha256.Sum256([]byte("world"))
+	_, err = r.Get(ctx, server.URL+"/data#sha256="+hex.EncodeToString(hash[:]), "")
+	require.ErrorIs(t, err, ErrIntegrity)
+
+	_, err = r.Get(ctx, server.URL+"/data#md5=abcd", "")
+	require.ErrorContains(t, err, "unsupported URI fragment")
+
+	_, err = r.Get(ctx, server.URL+"/large", "")
+	require.ErrorIs(t, err, ErrTooLarge)
+
+	_, err = r.Get(ctx, server.URL+"/redirect", "")
+	require.ErrorContains(t, err, "redirect to http is not allowed")
+
+	_, err = r.Get(ctx, server.URL+"/not-exist", "")
+	require.ErrorContains(t, err, "status 404")
+}
+
+func TestGetBareFromStorage(t *testing.T) {
+	storage, err := store.NewFileSystemStorage(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, storage.UploadFile("deals/1", "small", []byte("hello")))
+	require.NoError(t, storage.UploadFile("deals/1", "large", []byte("hello world")))
+
+	r := newTestResolver(t, storage, nil)
+	ctx := context.Background()
+
+	data, err := r.Get(ctx, "small", "deals/1")
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), data)
+
+	_, err = r.Get(ctx, "large", "deals/1")
+	require.ErrorIs(t, err, ErrTooLarge)
+
+	_, err = r.Get(ctx, "small", "deals/2")
+	require.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func TestGetInvalidURI(t *testing.T) {
+	r := newTestResolver(t, nil, nil)
+	ctx := context.Background()
+
+	_, err := r.Get(ctx, "http://example.com/data", "")
+	require.ErrorContains(t, err, "unsupported URI scheme: http")
+
+	_, err = r.Get(ctx, "s3://bucket/key", "")
+	require.ErrorContains(t, err, "s3-endpoint is not set")
+
+	_, err = r.Get(ctx, "ipfs://", "")
+	require.ErrorContains(t, err, "empty CID")
+}
+
+func TestGetHTTPSNotAllowed(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	// the address of the test server is a loopback address
+	r := newTestResolver(t, nil, nil)
+	_, err := r.Get(ctx, server.URL+"/data", "")
+	require.ErrorIs(t, err, ErrNotAllowed)
+
+	r = newTestResolver(t, nil, server)
+	r.conf.HTTPSAllowedHosts = []string{"example.com"}
+	_, err = r.Get(ctx, server.URL+"/data", "")
+	require.ErrorIs(t, err, ErrNotAllowed)
+}
+
+func TestGetS3NotAllowed(t *testing.T) {
+	conf := config.DefaultConfig()
+	conf.Storage.Backend = config.StorageBackendS3
+	conf.Storage.S3Endpoint = "http://127.0.0.1:1"
+	conf.Storage.S3Bucket = "oracle"
+	conf.Content.S3AllowedBuckets = []string{"oracle", "public"}
+
+	r, err := NewResolver(conf, ipfs.NewIpfs(conf), nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = r.Get(ctx, "s3://private/data", "deals/1")
+	require.ErrorIs(t, err, ErrNotAllowed)
+
+	// data of other deals in the bucket of the storage backend
+	_, err = r.Get(ctx, "s3://oracle/deals/2/data", "deals/1")
+	require.ErrorIs(t, err, ErrNotAllowed)
+	_, err = r.Get(ctx, "s3://oracle/deals/1/../2/data", "deals/1")
+	require.ErrorIs(t, err, ErrNotAllowed)
+	_, err = r.Get(ctx, "s3://oracle/deals/10/data", "deals/1")
+	require.ErrorIs(t, err, ErrNotAllowed)
+
+	// allowed, but the endpoint doesn't exist
+	_, err = r.Get(ctx, "s3://oracle/deals/1/data", "deals/1")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrNotAllowed)
+	_, err = r.Get(ctx, "s3://public/data", "deals/1")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrNotAllowed)
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1", "10.0.0.1", "172.16.0.1", "192.168.0.1", "169.254.169.254", "100.64.0.1", "0.0.0.0", "::1", "fe80::1", "fc00::1", "::ffff:127.0.0.1"} {
+		require.False(t, isPublicIP(net.ParseIP(addr)), addr)
+	}
+	for _, addr := range []string{"8.8.8.8", "1.1.1.1", "2001:4860:4860::8888"} {
+		require.True(t, isPublicIP(net.ParseIP(addr)), addr)
+	}
+}
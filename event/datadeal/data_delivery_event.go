@@ -39,7 +39,9 @@ func (e DataDeliveryVoteEvent) EventHandler(event ctypes.ResultEvent) error {
 	}
 
 	voteOption, deliveredCid, err := e.verifyAndGetVoteOption(dealID, dataHash)
-	if err != nil {
+	if errors.Is(err, errUnprovenQuery) {
+		return fmt.Errorf("failed to verify data delivery. dealID(%d). dataHash(%s): %w", dealID, dataHash, err)
+	} else if err != nil {
 		log.Infof("vote NO due to error while verify. dealID(%d). dataHash(%s): %v", dealID, dataHash, err)
 	}
 
@@ -85,7 +87,7 @@ func (e DataDeliveryVoteEvent) verifyAndGetVoteOption(dealID uint64, dataHash st
 
 	dataSale, err := e.reactor.QueryClient().GetDataSale(dataHash, dealID)
 	if err != nil {
-		return oracletypes.VOTE_OPTION_NO, "", fmt.Errorf("failed to get dataSale. %v", err)
+		return oracletypes.VOTE_OPTION_NO, "", fmt.Errorf("failed to get dataSale. %w", checkQueryError(err))
 	}
 
 	if dataSale.Status != datadealtypes.DATA_SALE_STATUS_DELIVERY_VOTING_PERIOD {
@@ -98,7 +100,7 @@ func (e DataDeliveryVoteEvent) verifyAndGetVoteOption(dealID uint64, dataHash st
 
 	deal, err := e.reactor.QueryClient().GetDeal(dealID)
 	if err != nil {
-		return oracletypes.VOTE_OPTION_NO, "", fmt.Errorf("failed to get deal. %v", err)
+		return oracletypes.VOTE_OPTION_NO, "", fmt.Errorf("failed to get deal. %w", checkQueryError(err))
 	}
 
 	deliveredCID, err := e.convertBuyerDataAndAddToIpfs(deal, dataSale, e.reactor.OraclePrivKey())
@@ -111,10 +113,10 @@ func (e DataDeliveryVoteEvent) verifyAndGetVoteOption(dealID uint64, dataHash st
 }
 
 func (e DataDeliveryVoteEvent) convertBuyerDataAndAddToIpfs(deal *datadealtypes.Deal, dataSale *datadealtypes.DataSale, oraclePrivKey *btcec.PrivateKey) (string, error) {
-	// get encrypted data from ipfs
-	encryptedDataBz, err := e.reactor.Ipfs().Get(dataSale.VerifiableCid)
+	// get encrypted data from ipfs or the storage
+	encryptedDataBz, err := getSellerData(e.reactor, dataSale)
 	if err != nil {
-		return "", fmt.Errorf("failed to get data from ipfs. verifiableCid(%s) .%v", dataSale.VerifiableCid, err)
+		return "", fmt.Errorf("failed to get data. verifiableCid(%s) .%v", dataSale.VerifiableCid, err)
 	}
 
 	// get shared key oraclePrivKey + sellerPublicKey
@@ -155,10 +157,10 @@ func (e DataDeliveryVoteEvent) convertBuyerDataAndAddToIpfs(deal *datadealtypes.
 		return "", fmt.Errorf("failed to encrypt data. %v", err)
 	}
 
-	// ipfs.add (decrypted data) & get CID
-	deliveredCid, err := e.reactor.Ipfs().Add(encryptDataWithBuyerKey)
+	// add the data encrypted for the buyer to ipfs or the storage & get CID
+	deliveredCid, err := addDeliveredData(e.reactor, deal.Id, encryptDataWithBuyerKey)
 	if err != nil {
-		return "", fmt.Errorf("failed to add data to Ipfs. %v", err)
+		return "", fmt.Errorf("failed to add delivered data. %v", err)
 	}
 
 	return deliveredCid, nil
@@ -43,7 +43,9 @@ func (e DataVerificationEvent) EventHandler(event ctypes.ResultEvent) error {
 	}
 
 	voteOption, err := e.verifyAndGetVoteOption(dealID, dataHash)
-	if err != nil {
+	if errors.Is(err, errUnprovenQuery) {
+		return fmt.Errorf("failed to verify data. dealID(%d). dataHash(%s): %w", dealID, dataHash, err)
+	} else if err != nil {
 		log.Infof("vote No due to error while verify. dealID(%d). dataHash(%s)", dealID, dataHash)
 	}
 
@@ -87,9 +89,9 @@ func (e DataVerificationEvent) compareDataHash(dataSale *datadealtypes.DataSale,
 }
 
 func (e DataVerificationEvent) convertSellerData(deal *datadealtypes.Deal, dataSale *datadealtypes.DataSale) ([]byte, error) {
-	encryptedDataBz, err := e.reactor.Ipfs().Get(dataSale.VerifiableCid)
+	encryptedDataBz, err := getSellerData(e.reactor, dataSale)
 	if err != nil {
-		log.Infof("failed to get data from IPFS: %v", err)
+		log.Infof("failed to get data: %v", err)
 		return nil, err
 	}
 
@@ -120,12 +122,12 @@ func (e DataVerificationEvent) convertSellerData(deal *datadealtypes.Deal, dataS
 func (e DataVerificationEvent) verifyAndGetVoteOption(dealID uint64, dataHash string) (oracletypes.VoteOption, error) {
 	deal, err := e.reactor.QueryClient().GetDeal(dealID)
 	if err != nil {
-		return oracletypes.VOTE_OPTION_NO, fmt.Errorf("failed to get deal. %v", err)
+		return oracletypes.VOTE_OPTION_NO, fmt.Errorf("failed to get deal. %w", checkQueryError(err))
 	}
 
 	dataSale, err := e.reactor.QueryClient().GetDataSale(dataHash, dealID)
 	if err != nil {
-		return oracletypes.VOTE_OPTION_NO, fmt.Errorf("failed to get dataSale (%v)", err)
+		return oracletypes.VOTE_OPTION_NO, fmt.Errorf("failed to get dataSale (%w)", checkQueryError(err))
 	}
 
 	if dataSale.Status != datadealtypes.DATA_SALE_STATUS_VERIFICATION_VOTING_PERIOD {
@@ -0,0 +1,22 @@
+package datadeal
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/medibloc/panacea-doracle/event"
+	"github.com/medibloc/panacea-doracle/panacea"
+)
+
+// errUnprovenQuery indicates that the queried data could not be verified, and its absence was not proven either.
+// In this case, the oracle doesn't vote, and the subscriber handles the event again later.
+// If it still fails after all retries, the event is dropped without a vote.
+var errUnprovenQuery = fmt.Errorf("failed to query verified data (%w)", event.ErrRetryable)
+
+// checkQueryError returns errUnprovenQuery unless err proves the absence of the data.
+func checkQueryError(err error) error {
+	if errors.Is(err, panacea.ErrNotFound) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", errUnprovenQuery, err)
+}
@@ -0,0 +1,37 @@
+package datadeal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	datadealtypes "github.com/medibloc/panacea-core/v2/x/datadeal/types"
+	"github.com/medibloc/panacea-doracle/event"
+)
+
+// dealStoragePath returns the path in the storage where the data of the deal is stored.
+func dealStoragePath(dealID uint64) string {
+	return fmt.Sprintf("deals/%d", dealID)
+}
+
+// getSellerData returns the encrypted data uploaded by the seller.
+// The verifiable CID can be a URI of IPFS, HTTPS or S3. A bare CID is fetched from IPFS or the storage in the config.
+func getSellerData(reactor event.Reactor, dataSale *datadealtypes.DataSale) ([]byte, error) {
+	return reactor.ContentResolver().Get(context.Background(), dataSale.VerifiableCid, dealStoragePath(dataSale.DealId))
+}
+
+// addDeliveredData adds the data encrypted for the buyer to IPFS or the storage in the config,
+// and returns its CID. In the storage, the hex-encoded SHA256 hash of the data is used as its CID.
+func addDeliveredData(reactor event.Reactor, dealID uint64, data []byte) (string, error) {
+	if reactor.Storage() == nil {
+		return reactor.Ipfs().Add(context.Background(), data)
+	}
+
+	hash := sha256.Sum256(data)
+	deliveredCid := hex.EncodeToString(hash[:])
+	if err := reactor.Storage().UploadFile(dealStoragePath(dealID), deliveredCid, data); err != nil {
+		return "", err
+	}
+	return deliveredCid, nil
+}
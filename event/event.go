@@ -1,9 +1,15 @@
 package event
 
 import (
+	"errors"
+
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 )
 
+// ErrRetryable is wrapped by errors of EventHandler if the event can be handled successfully later.
+// The subscriber handles the event again a few times.
+var ErrRetryable = errors.New("retryable")
+
 type Event interface {
 	GetEventQuery() string
 	EventHandler(event ctypes.ResultEvent) error
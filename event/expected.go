@@ -3,19 +3,26 @@ package event
 import (
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/medibloc/panacea-doracle/config"
+	"github.com/medibloc/panacea-doracle/content"
 	"github.com/medibloc/panacea-doracle/ipfs"
 	"github.com/medibloc/panacea-doracle/panacea"
 	"github.com/medibloc/panacea-doracle/sgx"
+	"github.com/medibloc/panacea-doracle/store"
 )
 
 // Reactor contains all ingredients needed for handling this type of event
 type Reactor interface {
 	GRPCClient() *panacea.GrpcClient
 	EnclaveInfo() *sgx.EnclaveInfo
+	TrustPolicy() *sgx.TrustPolicy
 	OracleAcc() *panacea.OracleAccount
 	OraclePrivKey() *btcec.PrivateKey
 	Config() *config.Config
 	QueryClient() *panacea.QueryClient
 	Ipfs() *ipfs.Ipfs
+	// Storage returns nil if IPFS is used as the storage of data.
+	Storage() store.Storage
+	// ContentResolver fetches data by the verifiable CID, which can be a URI of IPFS, HTTPS or S3.
+	ContentResolver() *content.Resolver
 	BroadcastTx(txBytes []byte) (int64, string, error)
 }
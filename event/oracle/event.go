@@ -10,7 +10,8 @@ import (
 	oracletypes "github.com/medibloc/panacea-core/v2/x/oracle/types"
 	"github.com/medibloc/panacea-doracle/crypto"
 	"github.com/medibloc/panacea-doracle/panacea"
-	"github.com/tendermint/tendermint/light/provider"
+	"github.com/medibloc/panacea-doracle/sgx"
+	log "github.com/sirupsen/logrus"
 )
 
 func makeMsgVoteOracleRegistration(uniqueID, voterUniqueID, voterAddr, votingTargetAddr string, voteOption oracletypes.VoteOption, oraclePrivKey, nodePubKey, nonce []byte) (*oracletypes.MsgVoteOracleRegistration, error) {
@@ -83,15 +84,13 @@ func makeMsgVoteOracleRegistrationWithSignature(registrationVote *oracletypes.Or
 	return msgVoteOracleRegistration, nil
 }
 
+// verifyTrustedBlockInfo verifies that the block hash is the hash of the block at the height.
+// The light block is not proven to be absent even if the RPC node doesn't have it (e.g. it has been pruned),
+// so the failures of getting the light block are returned as retryable errors.
 func verifyTrustedBlockInfo(queryClient *panacea.QueryClient, height int64, blockHash []byte) error {
 	block, err := queryClient.GetLightBlock(height)
 	if err != nil {
-		switch err {
-		case provider.ErrLightBlockNotFound, provider.ErrHeightTooHigh:
-			return fmt.Errorf("not found light block. %w", err)
-		default:
-			return err
-		}
+		return fmt.Errorf("failed to get light block. height(%v). %w", height, checkQueryError(err))
 	}
 
 	if !bytes.Equal(block.Hash().Bytes(), blockHash) {
@@ -104,3 +103,18 @@ func verifyTrustedBlockInfo(queryClient *panacea.QueryClient, height int64, bloc
 
 	return nil
 }
+
+// logReportVerification records the result of verifying the remote report of the oracle, for auditing votes.
+func logReportVerification(oracleRegistration *oracletypes.OracleRegistration, result *sgx.VerificationResult, err error) {
+	logger := log.WithFields(result.LogFields()).WithFields(log.Fields{
+		"audit":   "remote_report_verification",
+		"address": oracleRegistration.Address,
+	})
+	if err != nil {
+		logger.Warnf("remote report rejected. uniqueID(%s): %v", oracleRegistration.UniqueId, err)
+	} else if result.TCBAction == sgx.TCBActionWarn {
+		logger.Warnf("remote report accepted with warnings. uniqueID(%s)", oracleRegistration.UniqueId)
+	} else {
+		logger.Infof("remote report accepted. uniqueID(%s)", oracleRegistration.UniqueId)
+	}
+}
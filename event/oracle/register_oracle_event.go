@@ -2,12 +2,12 @@ package oracle
 
 import (
 	"crypto/sha256"
+	"errors"
 	"fmt"
 
 	oracletypes "github.com/medibloc/panacea-core/v2/x/oracle/types"
 	"github.com/medibloc/panacea-doracle/event"
 	"github.com/medibloc/panacea-doracle/panacea"
-	"github.com/medibloc/panacea-doracle/sgx"
 	log "github.com/sirupsen/logrus"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 )
@@ -79,6 +79,12 @@ func (e RegisterOracleEvent) verifyAndGetMsgVoteOracleRegistration(uniqueID, vot
 	} else {
 		oracleRegistration, err := queryClient.GetOracleRegistration(votingTargetAddress, uniqueID)
 		if err != nil {
+			// vote No only if the absence of oracleRegistration is proven.
+			// other errors are returned without voting, so that they can be retried.
+			if err := checkQueryError(err); !errors.Is(err, panacea.ErrNotFound) {
+				return nil, fmt.Errorf("failed to get oracleRegistration. uniqueID(%s), address(%s): %w", uniqueID, votingTargetAddress, err)
+			}
+			log.Infof("vote No due to oracleRegistration not found. uniqueID(%s), address(%s)", uniqueID, votingTargetAddress)
 			return makeMsgVoteOracleRegistrationVoteTypeNo(
 				voterUniqueID,
 				voterUniqueID,
@@ -89,7 +95,9 @@ func (e RegisterOracleEvent) verifyAndGetMsgVoteOracleRegistration(uniqueID, vot
 		}
 
 		voteOption, err := e.verifyAndGetVoteOption(oracleRegistration)
-		if err != nil {
+		if errors.Is(err, event.ErrRetryable) {
+			return nil, err
+		} else if err != nil {
 			log.Infof("vote No due to error while verify: %v", err)
 		}
 
@@ -109,6 +117,7 @@ func (e RegisterOracleEvent) verifyAndGetMsgVoteOracleRegistration(uniqueID, vot
 // verifyAndGetVoteOption performs a verification to determine a vote.
 // - Verify that trustedBlockInfo registered in OracleRegistration is valid
 // - Verify that the RemoteReport is valid
+// If the verification could not be completed, an error wrapping event.ErrRetryable is returned, and the vote must not be cast.
 func (e RegisterOracleEvent) verifyAndGetVoteOption(oracleRegistration *oracletypes.OracleRegistration) (oracletypes.VoteOption, error) {
 	if err := verifyTrustedBlockInfo(e.reactor.QueryClient(), oracleRegistration.TrustedBlockHeight, oracleRegistration.TrustedBlockHash); err != nil {
 		return oracletypes.VOTE_OPTION_NO, err
@@ -116,9 +125,13 @@ func (e RegisterOracleEvent) verifyAndGetVoteOption(oracleRegistration *oraclety
 
 	nodePubKeyHash := sha256.Sum256(oracleRegistration.NodePubKey)
 
-	if err := sgx.VerifyRemoteReport(oracleRegistration.NodePubKeyRemoteReport, nodePubKeyHash[:], *e.reactor.EnclaveInfo()); err != nil {
-		log.Warnf("failed to verification report. uniqueID(%s), address(%s), err(%v)", oracleRegistration.UniqueId, oracleRegistration.Address, err)
-		return oracletypes.VOTE_OPTION_NO, nil
+	// if no unique ID is trusted by the config, trust only the unique ID of this oracle, which is the one being registered.
+	policy := e.reactor.TrustPolicy().WithDefaultUniqueIDs(e.reactor.EnclaveInfo().UniqueID)
+
+	result, err := policy.Verify(oracleRegistration.NodePubKeyRemoteReport, nodePubKeyHash[:])
+	logReportVerification(oracleRegistration, result, err)
+	if err != nil {
+		return oracletypes.VOTE_OPTION_NO, fmt.Errorf("failed to verify report. uniqueID(%s), address(%s): %v", oracleRegistration.UniqueId, oracleRegistration.Address, err)
 	} else {
 		return oracletypes.VOTE_OPTION_YES, nil
 	}
@@ -1,12 +1,15 @@
 package oracle
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
 	"github.com/cosmos/cosmos-sdk/types/bech32"
 	oracletypes "github.com/medibloc/panacea-core/v2/x/oracle/types"
+	"github.com/medibloc/panacea-doracle/event"
 	"github.com/medibloc/panacea-doracle/panacea"
 	"github.com/stretchr/testify/require"
 )
@@ -50,3 +53,15 @@ func TestMakeOracleRegistrationVoteNo(t *testing.T) {
 	require.Equal(t, voteOption, orv.OracleRegistrationVote.VoteOption)
 	require.Nil(t, orv.OracleRegistrationVote.EncryptedOraclePrivKey)
 }
+
+func TestCheckQueryError(t *testing.T) {
+	// the absence is proven, so the oracle votes No
+	err := checkQueryError(fmt.Errorf("failed to get: %w", panacea.ErrNotFound))
+	require.ErrorIs(t, err, panacea.ErrNotFound)
+	require.NotErrorIs(t, err, event.ErrRetryable)
+
+	// the oracle doesn't vote until the query succeeds
+	err = checkQueryError(errors.New("connection refused"))
+	require.ErrorIs(t, err, event.ErrRetryable)
+	require.ErrorContains(t, err, "connection refused")
+}
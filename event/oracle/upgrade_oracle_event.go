@@ -2,16 +2,14 @@ package oracle
 
 import (
 	"bytes"
-	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
 
-	"github.com/edgelesssys/ego/enclave"
 	oracletypes "github.com/medibloc/panacea-core/v2/x/oracle/types"
 	"github.com/medibloc/panacea-doracle/crypto"
 	"github.com/medibloc/panacea-doracle/event"
 	"github.com/medibloc/panacea-doracle/panacea"
-	"github.com/medibloc/panacea-doracle/sgx"
 	log "github.com/sirupsen/logrus"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 )
@@ -70,12 +68,19 @@ func (e UpgradeOracleEvent) verifyAndGetMsgVoteOracleRegistration(uniqueID, voti
 
 	oracleRegistration, err := queryClient.GetOracleRegistration(votingTargetAddress, uniqueID)
 	if err != nil {
-		log.Infof("failed to get oracleRegistration. uniqueID(%s), address(%s). %v", uniqueID, votingTargetAddress, err)
+		// vote No only if the absence of oracleRegistration is proven.
+		// other errors are returned without voting, so that they can be retried.
+		if err := checkQueryError(err); !errors.Is(err, panacea.ErrNotFound) {
+			return nil, fmt.Errorf("failed to get oracleRegistration. uniqueID(%s), address(%s): %w", uniqueID, votingTargetAddress, err)
+		}
+		log.Infof("vote No due to oracleRegistration not found. uniqueID(%s), address(%s)", uniqueID, votingTargetAddress)
 		return makeMsgVoteOracleRegistrationVoteTypeNo(uniqueID, voterUniqueID, voterAddress, votingTargetAddress, oraclePrivKeyBz)
 	}
 
 	voteOption, err := e.verifyAndGetVoteOption(oracleRegistration)
-	if err != nil {
+	if errors.Is(err, event.ErrRetryable) {
+		return nil, err
+	} else if err != nil {
 		log.Infof("vote No due to error while verify: %v", err)
 	}
 
@@ -92,11 +97,14 @@ func (e UpgradeOracleEvent) verifyAndGetMsgVoteOracleRegistration(uniqueID, voti
 
 }
 
+// verifyAndGetVoteOption performs a verification to determine a vote.
+// If the verification could not be completed, an error wrapping event.ErrRetryable is returned, and the vote must not be cast.
 func (e UpgradeOracleEvent) verifyAndGetVoteOption(oracleRegistration *oracletypes.OracleRegistration) (oracletypes.VoteOption, error) {
 	queryClient := e.reactor.QueryClient()
 	upgradeInfo, err := queryClient.GetOracleUpgradeInfo()
 	if err != nil {
-		return oracletypes.VOTE_OPTION_NO, fmt.Errorf("failed to get oracle upgrade info. %v", err)
+		// the oracle upgrade info is proven to be absent if err is panacea.ErrNotFound
+		return oracletypes.VOTE_OPTION_NO, fmt.Errorf("failed to get oracle upgrade info. %w", checkQueryError(err))
 	}
 	if upgradeInfo.UniqueId != oracleRegistration.UniqueId {
 		return oracletypes.VOTE_OPTION_NO, fmt.Errorf("oracle's uniqueID does not match the uniqueID being upgraded. expected uniqueID(%s), oracle's uniqueID(%s), ",
@@ -118,38 +126,20 @@ func (e UpgradeOracleEvent) verifyAndGetVoteOption(oracleRegistration *oracletyp
 }
 
 func (e UpgradeOracleEvent) verifyRemoteReport(oracleRegistration *oracletypes.OracleRegistration) error {
-	reportBz := oracleRegistration.NodePubKeyRemoteReport
-	expectedNodePubKeyHash := crypto.KDFSHA256(oracleRegistration.NodePubKey)
-	expectedUniqueID := oracleRegistration.UniqueId
-
-	enclaveInfo := e.reactor.EnclaveInfo()
-	report, err := enclave.VerifyRemoteReport(reportBz)
+	uniqueID, err := hex.DecodeString(oracleRegistration.UniqueId)
 	if err != nil {
-		return err
+		return fmt.Errorf("invalid uniqueID format. uniqueID(%s). %w", oracleRegistration.UniqueId, err)
 	}
 
-	if report.SecurityVersion < sgx.PromisedMinSecurityVersion {
-		return fmt.Errorf("invalid security version in the report")
-	}
-	if !bytes.Equal(report.ProductID, enclaveInfo.ProductID) {
-		return fmt.Errorf("invalid product ID in the report")
-	}
-	if !bytes.Equal(report.SignerID, enclaveInfo.SignerID) {
-		return fmt.Errorf("invalid signer ID in the report")
-	}
-	uniqueID, err := hex.DecodeString(expectedUniqueID)
-	if err != nil {
-		return fmt.Errorf("invalid uniqueID format. uniqueID(%s). %w", uniqueID, err)
-	}
-	if !bytes.Equal(report.UniqueID, uniqueID) {
-		return fmt.Errorf("invalid unique ID in the report")
-	}
-	if !bytes.Equal(report.Data[:len(expectedNodePubKeyHash)], expectedNodePubKeyHash) {
-		return fmt.Errorf("invalid data in the report. expected(%s), got(%s)",
-			base64.StdEncoding.EncodeToString(expectedNodePubKeyHash),
-			base64.StdEncoding.EncodeToString(report.Data[:len(expectedNodePubKeyHash)]),
-		)
+	// if no unique ID is trusted by the config, trust only the unique ID being upgraded.
+	policy := e.reactor.TrustPolicy().WithDefaultUniqueIDs(uniqueID)
+
+	result, err := policy.Verify(oracleRegistration.NodePubKeyRemoteReport, crypto.KDFSHA256(oracleRegistration.NodePubKey))
+	// the unique ID trusted by the config may be different from the one being upgraded.
+	if err == nil && !bytes.Equal(result.Report.UniqueID, uniqueID) {
+		err = fmt.Errorf("unique ID in the report does not match the uniqueID being upgraded: %X", result.Report.UniqueID)
 	}
+	logReportVerification(oracleRegistration, result, err)
 
-	return nil
+	return err
 }
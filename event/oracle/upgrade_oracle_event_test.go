@@ -72,6 +72,8 @@ func (suite *upgradeOracleEventTestSuite) TestVerifyAndGetVoteOptionNotFoundOrac
 	voteOption, err := e.verifyAndGetVoteOption(oracleRegistration)
 
 	require.ErrorContains(suite.T(), err, "failed to get oracle upgrade info.")
+	require.ErrorIs(suite.T(), err, panacea.ErrNotFound)
+	require.NotErrorIs(suite.T(), err, event.ErrRetryable)
 	require.Equal(suite.T(), oracletypes.VOTE_OPTION_NO, voteOption)
 }
 
@@ -84,20 +86,15 @@ func (suite *upgradeOracleEventTestSuite) prepare() (*panacea.TrustedBlockInfo,
 		TrustedBlockHash:   hash,
 	}
 
-	conf := &config.Config{
-		BaseConfig: config.BaseConfig{
-			OracleMnemonic: suite.validatorMnemonic,
-			OracleAccNum:   0,
-			OracleAccIndex: 0,
-		},
-		Panacea: config.PanaceaConfig{
-			GRPCAddr:                suite.PanaceaEndpoint("tcp", 9090),
-			RPCAddr:                 suite.PanaceaEndpoint("tcp", 26657),
-			ChainID:                 suite.chainID,
-			LightClientPrimaryAddr:  suite.PanaceaEndpoint("tcp", 26657),
-			LightClientWitnessAddrs: []string{suite.PanaceaEndpoint("tcp", 26657)},
-		},
-	}
+	conf := config.DefaultConfig()
+	conf.OracleMnemonic = suite.validatorMnemonic
+	conf.OracleAccNum = 0
+	conf.OracleAccIndex = 0
+	conf.Panacea.GRPCAddr = suite.PanaceaEndpoint("tcp", 9090)
+	conf.Panacea.RPCAddr = suite.PanaceaEndpoint("tcp", 26657)
+	conf.Panacea.ChainID = suite.chainID
+	conf.Panacea.LightClientPrimaryAddr = suite.PanaceaEndpoint("tcp", 26657)
+	conf.Panacea.LightClientWitnessAddrs = []string{suite.PanaceaEndpoint("tcp", 26657)}
 
 	return trustedBlockInfo, conf
 }
@@ -2,10 +2,22 @@ package event
 
 import (
 	"context"
+	"errors"
+	"sort"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+const (
+	// maxEventRetries is the maximum number of retries of an event whose handler returned ErrRetryable.
+	maxEventRetries = 5
+	// eventRetryBaseDelay is the delay of the first retry. The delay is doubled for each retry.
+	eventRetryBaseDelay = 5 * time.Second
+	// maxPendingEventRetries is the maximum number of events waiting for retries per subscription.
+	maxPendingEventRetries = 100
 )
 
 type PanaceaSubscriber struct {
@@ -53,17 +65,64 @@ func (s *PanaceaSubscriber) subscribe(event Event) error {
 		return err
 	}
 
-	go func(e Event) {
-		for tx := range txs {
-			if err := e.EventHandler(tx); err != nil {
-				log.Errorf("failed to handle event '%s': %v", query, err)
-			}
-		}
-	}(event)
+	go handleEvents(event, txs, eventRetryBaseDelay)
 
 	return nil
 }
 
+// eventRetry is an event which will be handled again at the time.
+type eventRetry struct {
+	tx      ctypes.ResultEvent
+	attempt int
+	at      time.Time
+}
+
+// handleEvents handles events one by one, including retries, so that handlers are never run concurrently.
+// Events are still received while waiting for retries.
+func handleEvents(e Event, txs <-chan ctypes.ResultEvent, baseDelay time.Duration) {
+	query := e.GetEventQuery()
+	var retries []eventRetry
+
+	handle := func(tx ctypes.ResultEvent, attempt int) {
+		err := e.EventHandler(tx)
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, ErrRetryable) || attempt >= maxEventRetries {
+			log.Errorf("failed to handle event '%s': %v", query, err)
+			return
+		}
+		if len(retries) >= maxPendingEventRetries {
+			log.Errorf("failed to handle event '%s' and too many events are waiting for retries: %v", query, err)
+			return
+		}
+
+		delay := baseDelay << attempt
+		log.Warnf("failed to handle event '%s', retrying in %v (%d/%d): %v", query, delay, attempt+1, maxEventRetries, err)
+		retries = append(retries, eventRetry{tx: tx, attempt: attempt + 1, at: time.Now().Add(delay)})
+		sort.SliceStable(retries, func(i, j int) bool { return retries[i].at.Before(retries[j].at) })
+	}
+
+	for {
+		var retryC <-chan time.Time
+		if len(retries) > 0 {
+			retryC = time.After(time.Until(retries[0].at))
+		}
+
+		select {
+		case tx, ok := <-txs:
+			if !ok {
+				return
+			}
+			handle(tx, 0)
+		case <-retryC:
+			retry := retries[0]
+			retries = retries[1:]
+			handle(retry.tx, retry.attempt)
+		}
+	}
+}
+
 func (s *PanaceaSubscriber) Close() error {
 	log.Infof("closing Panacea event subscriber")
 	return s.client.Stop()
@@ -0,0 +1,74 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+// failingEvent fails to handle each event the given number of times.
+type failingEvent struct {
+	mutex    sync.Mutex
+	failures int
+	err      error
+	calls    map[string]int
+}
+
+func (e *failingEvent) GetEventQuery() string {
+	return "test"
+}
+
+func (e *failingEvent) EventHandler(event ctypes.ResultEvent) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.calls[event.Query]++
+	if e.calls[event.Query] <= e.failures {
+		return e.err
+	}
+	return nil
+}
+
+func (e *failingEvent) callsOf(query string) int {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.calls[query]
+}
+
+func TestHandleEventsRetry(t *testing.T) {
+	testCases := []struct {
+		name          string
+		failures      int
+		err           error
+		expectedCalls int
+	}{
+		{"success after retries", 2, fmt.Errorf("unproven: %w", ErrRetryable), 3},
+		{"retries exhausted", 100, fmt.Errorf("unproven: %w", ErrRetryable), maxEventRetries + 1},
+		{"not retryable", 100, errors.New("invalid event"), 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &failingEvent{failures: tc.failures, err: tc.err, calls: map[string]int{}}
+			txs := make(chan ctypes.ResultEvent)
+			defer close(txs)
+			go handleEvents(e, txs, time.Millisecond)
+
+			txs <- ctypes.ResultEvent{Query: "first"}
+			// the next event is handled while the first one is waiting for retries
+			txs <- ctypes.ResultEvent{Query: "second"}
+
+			require.Eventually(t, func() bool {
+				return e.callsOf("first") == tc.expectedCalls && e.callsOf("second") == tc.expectedCalls
+			}, 5*time.Second, 10*time.Millisecond)
+
+			time.Sleep(100 * time.Millisecond)
+			require.Equal(t, tc.expectedCalls, e.callsOf("first"))
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// Status is the health of a component which runs in the background.
+type Status struct {
+	Healthy             bool       `json:"healthy"`
+	LastCheckedAt       time.Time  `json:"last_checked_at"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastErrorAt         *time.Time `json:"last_error_at,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+}
+
+// Registry keeps the health of components, so that failures of background jobs can be exposed instead of only being logged.
+type Registry struct {
+	mutex    sync.RWMutex
+	statuses map[string]Status
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		statuses: make(map[string]Status),
+	}
+}
+
+// Report records the result of the last run of the component.
+// A nil err marks the component healthy, and a non-nil err marks it unhealthy.
+func (r *Registry) Report(component string, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	status := r.statuses[component]
+	status.LastCheckedAt = now
+
+	if err == nil {
+		status.Healthy = true
+		status.ConsecutiveFailures = 0
+	} else {
+		status.Healthy = false
+		status.LastError = err.Error()
+		status.LastErrorAt = &now
+		status.ConsecutiveFailures++
+	}
+
+	r.statuses[component] = status
+}
+
+// Statuses returns a copy of the statuses of all components reported.
+func (r *Registry) Statuses() map[string]Status {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	statuses := make(map[string]Status, len(r.statuses))
+	for component, status := range r.statuses {
+		statuses[component] = status
+	}
+	return statuses
+}
@@ -0,0 +1,40 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	require.Empty(t, registry.Statuses())
+
+	registry.Report("refresh", nil)
+	status := registry.Statuses()["refresh"]
+	require.True(t, status.Healthy)
+	require.Nil(t, status.LastErrorAt)
+
+	statusJSON, err := json.Marshal(status)
+	require.NoError(t, err)
+	require.NotContains(t, string(statusJSON), "last_error_at")
+
+	registry.Report("refresh", errors.New("connection refused"))
+	registry.Report("refresh", errors.New("connection refused"))
+
+	status = registry.Statuses()["refresh"]
+	require.False(t, status.Healthy)
+	require.Equal(t, "connection refused", status.LastError)
+	require.NotNil(t, status.LastErrorAt)
+	require.Equal(t, 2, status.ConsecutiveFailures)
+
+	registry.Report("refresh", nil)
+
+	status = registry.Statuses()["refresh"]
+	require.True(t, status.Healthy)
+	require.Equal(t, 0, status.ConsecutiveFailures)
+	// the last error is kept for troubleshooting
+	require.Equal(t, "connection refused", status.LastError)
+}
@@ -6,10 +6,12 @@ import (
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/medibloc/panacea-doracle/config"
+	"github.com/medibloc/panacea-doracle/content"
 	"github.com/medibloc/panacea-doracle/event"
 	"github.com/medibloc/panacea-doracle/ipfs"
 	"github.com/medibloc/panacea-doracle/panacea"
 	"github.com/medibloc/panacea-doracle/sgx"
+	"github.com/medibloc/panacea-doracle/store"
 	dbm "github.com/tendermint/tm-db"
 )
 
@@ -22,6 +24,7 @@ type TestServiceWithoutSGX struct {
 	grpcClient  *panacea.GrpcClient
 	subscriber  *event.PanaceaSubscriber
 	ipfs        *ipfs.Ipfs
+	resolver    *content.Resolver
 }
 
 func (s *TestServiceWithoutSGX) BroadcastTx(txBytes []byte) (int64, string, error) {
@@ -63,7 +66,12 @@ func NewTestServiceWithoutSGX(conf *config.Config, info *panacea.TrustedBlockInf
 		return nil, err
 	}
 
-	ipfs := ipfs.NewIpfs(conf.Ipfs.IpfsNodeAddr)
+	ipfs := ipfs.NewIpfs(conf)
+
+	resolver, err := content.NewResolver(conf, ipfs, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	return &TestServiceWithoutSGX{
 		conf:          conf,
@@ -73,6 +81,7 @@ func NewTestServiceWithoutSGX(conf *config.Config, info *panacea.TrustedBlockInf
 		grpcClient:    grpcClient,
 		subscriber:    panaceaSubscriber,
 		ipfs:          ipfs,
+		resolver:      resolver,
 	}, nil
 }
 
@@ -92,6 +101,10 @@ func (s *TestServiceWithoutSGX) EnclaveInfo() *sgx.EnclaveInfo {
 	return nil
 }
 
+func (s *TestServiceWithoutSGX) TrustPolicy() *sgx.TrustPolicy {
+	return nil
+}
+
 func (s *TestServiceWithoutSGX) GRPCClient() *panacea.GrpcClient {
 	return s.grpcClient
 }
@@ -100,6 +113,14 @@ func (s *TestServiceWithoutSGX) QueryClient() *panacea.QueryClient {
 	return s.queryClient
 }
 
+func (s *TestServiceWithoutSGX) Storage() store.Storage {
+	return nil
+}
+
+func (s *TestServiceWithoutSGX) ContentResolver() *content.Resolver {
+	return s.resolver
+}
+
 func (s *TestServiceWithoutSGX) Ipfs() *ipfs.Ipfs {
 	return s.ipfs
 }
@@ -0,0 +1,279 @@
+package ipfs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// maxBlockSize is the maximum size of a block. IPFS nodes don't exchange blocks larger than it.
+	maxBlockSize = 4 << 20
+	// maxDAGDepth is the maximum depth of the DAG of a file, which is much deeper than what chunkers produce.
+	maxDAGDepth = 32
+)
+
+// UnixFS data types. https://github.com/ipfs/specs/blob/main/UNIXFS.md
+const (
+	unixfsRaw  = 0
+	unixfsFile = 2
+)
+
+// ErrCIDMismatch is returned if a block from the IPFS node doesn't match its CID.
+var ErrCIDMismatch = errors.New("data doesn't match the CID")
+
+// getVerified gets the file of the CID block by block, and checks that every block matches its CID.
+// So, the data is trusted even if the IPFS node is not.
+// The CID must be a raw block or a UnixFS file in dag-pb.
+func (i *Ipfs) getVerified(ctx context.Context, c cid.Cid) ([]byte, error) {
+	f := &dagFile{ipfs: i}
+	if _, err := f.appendNode(ctx, c, 0); err != nil {
+		return nil, err
+	}
+	return f.buf.Bytes(), nil
+}
+
+// getBlock gets the block of the CID, and checks that the hash of the block matches the CID.
+func (i *Ipfs) getBlock(ctx context.Context, c cid.Cid) ([]byte, error) {
+	switch c.Prefix().MhType {
+	case mh.SHA2_256, mh.SHA2_512:
+	default:
+		return nil, fmt.Errorf("unsupported hash function of CID %s: %d", c, c.Prefix().MhType)
+	}
+
+	resp, err := i.sh.Request("block/get", c.String()).Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	defer resp.Output.Close()
+
+	block, err := io.ReadAll(io.LimitReader(resp.Output, maxBlockSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block %s: %w", c, err)
+	}
+	if len(block) > maxBlockSize {
+		return nil, fmt.Errorf("block %s is larger than %d bytes", c, maxBlockSize)
+	}
+
+	sum, err := c.Prefix().Sum(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute CID of block %s: %w", c, err)
+	}
+	if !sum.Equals(c) {
+		return nil, fmt.Errorf("%w: block %s, got %s", ErrCIDMismatch, c, sum)
+	}
+	return block, nil
+}
+
+// dagFile collects the data of a file from the leaves of its DAG in order.
+type dagFile struct {
+	ipfs *Ipfs
+	buf  bytes.Buffer
+}
+
+// appendNode appends the data of the node and its children, and returns the size of the appended data.
+func (f *dagFile) appendNode(ctx context.Context, c cid.Cid, depth int) (uint64, error) {
+	if depth > maxDAGDepth {
+		return 0, fmt.Errorf("DAG is deeper than %d", maxDAGDepth)
+	}
+
+	block, err := f.ipfs.getBlock(ctx, c)
+	if err != nil {
+		return 0, err
+	}
+
+	switch c.Type() {
+	case cid.Raw:
+		return f.write(block)
+	case cid.DagProtobuf:
+	default:
+		return 0, fmt.Errorf("unsupported codec of CID %s: %d", c, c.Type())
+	}
+
+	node, err := decodePBNode(block)
+	if err != nil {
+		return 0, fmt.Errorf("invalid dag-pb node %s: %w", c, err)
+	}
+	data, err := decodeUnixFSData(node.data)
+	if err != nil {
+		return 0, fmt.Errorf("invalid UnixFS data of %s: %w", c, err)
+	}
+	if data.dataType != unixfsRaw && data.dataType != unixfsFile {
+		return 0, fmt.Errorf("%s is not a file: UnixFS type %d", c, data.dataType)
+	}
+	if len(data.blockSizes) != 0 && len(data.blockSizes) != len(node.links) {
+		return 0, fmt.Errorf("invalid UnixFS data of %s: %d block sizes for %d links", c, len(data.blockSizes), len(node.links))
+	}
+
+	size, err := f.write(data.data)
+	if err != nil {
+		return 0, err
+	}
+	for idx, link := range node.links {
+		childSize, err := f.appendNode(ctx, link, depth+1)
+		if err != nil {
+			return 0, err
+		}
+		if len(data.blockSizes) != 0 && childSize != data.blockSizes[idx] {
+			return 0, fmt.Errorf("invalid UnixFS data of %s: block size %d, got %d", c, data.blockSizes[idx], childSize)
+		}
+		size += childSize
+	}
+
+	if data.hasFileSize && size != data.fileSize {
+		return 0, fmt.Errorf("invalid UnixFS data of %s: file size %d, got %d", c, data.fileSize, size)
+	}
+	return size, nil
+}
+
+func (f *dagFile) write(data []byte) (uint64, error) {
+	if int64(f.buf.Len()+len(data)) > f.ipfs.maxSize {
+		return 0, fmt.Errorf("%w: more than %d bytes", ErrTooLarge, f.ipfs.maxSize)
+	}
+	f.buf.Write(data)
+	return uint64(len(data)), nil
+}
+
+// pbNode is a dag-pb node. https://ipld.io/specs/codecs/dag-pb/spec/
+type pbNode struct {
+	links []cid.Cid
+	data  []byte
+}
+
+func decodePBNode(block []byte) (*pbNode, error) {
+	node := &pbNode{}
+	err := decodeFields(block, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			node.data = value
+		case num == 2 && typ == protowire.BytesType:
+			link, err := decodePBLink(value)
+			if err != nil {
+				return err
+			}
+			node.links = append(node.links, link)
+		default:
+			return fmt.Errorf("unexpected field %d", num)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// decodePBLink returns the CID of a link. The name and the size of the link are not needed to read a file.
+func decodePBLink(bz []byte) (cid.Cid, error) {
+	var link cid.Cid
+	err := decodeFields(bz, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			c, err := cid.Cast(value)
+			if err != nil {
+				return fmt.Errorf("invalid CID of link: %w", err)
+			}
+			link = c
+		case num == 2 && typ == protowire.BytesType, num == 3 && typ == protowire.VarintType:
+		default:
+			return fmt.Errorf("unexpected field %d of link", num)
+		}
+		return nil
+	})
+	if err != nil {
+		return cid.Undef, err
+	}
+	if !link.Defined() {
+		return cid.Undef, errors.New("link without CID")
+	}
+	return link, nil
+}
+
+// unixfsData is the UnixFS data in a dag-pb node.
+type unixfsData struct {
+	dataType    uint64
+	data        []byte
+	fileSize    uint64
+	hasFileSize bool
+	blockSizes  []uint64
+}
+
+func decodeUnixFSData(bz []byte) (*unixfsData, error) {
+	data := &unixfsData{}
+	hasType := false
+	err := decodeFields(bz, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			data.dataType = varint
+			hasType = true
+		case num == 2 && typ == protowire.BytesType:
+			data.data = value
+		case num == 3 && typ == protowire.VarintType:
+			data.fileSize = varint
+			data.hasFileSize = true
+		case num == 4 && typ == protowire.VarintType:
+			data.blockSizes = append(data.blockSizes, varint)
+		case num == 4 && typ == protowire.BytesType:
+			// packed block sizes
+			for len(value) > 0 {
+				blockSize, n := protowire.ConsumeVarint(value)
+				if n < 0 {
+					return protowire.ParseError(n)
+				}
+				data.blockSizes = append(data.blockSizes, blockSize)
+				value = value[n:]
+			}
+		default:
+			// hashType, fanout, mode and mtime don't change the data of a file.
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !hasType {
+		return nil, errors.New("no UnixFS type")
+	}
+	return data, nil
+}
+
+// decodeFields calls the handler for each field of the protobuf message,
+// with the value of bytes fields or the value of varint fields.
+func decodeFields(bz []byte, handler func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error) error {
+	for len(bz) > 0 {
+		num, typ, n := protowire.ConsumeTag(bz)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		bz = bz[n:]
+
+		var value []byte
+		var varint uint64
+		switch typ {
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(bz)
+		case protowire.VarintType:
+			varint, n = protowire.ConsumeVarint(bz)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, bz)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		bz = bz[n:]
+
+		if err := handler(num, typ, value, varint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package ipfs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/medibloc/panacea-doracle/ipfs"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func rawBlockCid(t *testing.T, block []byte) cid.Cid {
+	c, err := cid.Prefix{Version: 1, Codec: cid.Raw, MhType: mh.SHA2_256, MhLength: -1}.Sum(block)
+	require.NoError(t, err)
+	return c
+}
+
+// newFileNode returns a dag-pb block of a UnixFS file whose data is in the links, and its CID.
+func newFileNode(t *testing.T, unixfsType uint64, links []cid.Cid, blockSizes []uint64) (cid.Cid, []byte) {
+	var data []byte
+	data = protowire.AppendTag(data, 1, protowire.VarintType)
+	data = protowire.AppendVarint(data, unixfsType)
+	var fileSize uint64
+	for _, blockSize := range blockSizes {
+		fileSize += blockSize
+	}
+	data = protowire.AppendTag(data, 3, protowire.VarintType)
+	data = protowire.AppendVarint(data, fileSize)
+	for _, blockSize := range blockSizes {
+		data = protowire.AppendTag(data, 4, protowire.VarintType)
+		data = protowire.AppendVarint(data, blockSize)
+	}
+
+	var block []byte
+	for _, link := range links {
+		var linkBz []byte
+		linkBz = protowire.AppendTag(linkBz, 1, protowire.BytesType)
+		linkBz = protowire.AppendBytes(linkBz, link.Bytes())
+		linkBz = protowire.AppendTag(linkBz, 2, protowire.BytesType)
+		linkBz = protowire.AppendString(linkBz, "")
+		block = protowire.AppendTag(block, 2, protowire.BytesType)
+		block = protowire.AppendBytes(block, linkBz)
+	}
+	block = protowire.AppendTag(block, 1, protowire.BytesType)
+	block = protowire.AppendBytes(block, data)
+
+	c, err := cid.Prefix{Version: 0, Codec: cid.DagProtobuf, MhType: mh.SHA2_256, MhLength: -1}.Sum(block)
+	require.NoError(t, err)
+	return c, block
+}
+
+func TestIpfsGetVerifiesBlocks(t *testing.T) {
+	hello := rawBlockCid(t, []byte("hello "))
+	world := rawBlockCid(t, []byte("world"))
+	file, fileBlock := newFileNode(t, 2, []cid.Cid{hello, world}, []uint64{6, 5})
+	dir, dirBlock := newFileNode(t, 1, nil, nil)
+
+	node := &fakeIpfsNode{
+		blocks: map[string][]byte{
+			hello.String(): []byte("hello "),
+			world.String(): []byte("world"),
+			file.String():  fileBlock,
+			dir.String():   dirBlock,
+		},
+		statSizes: map[string]uint64{file.String(): 11},
+	}
+	i := newTestIpfs(t, node, 100)
+	ctx := context.Background()
+
+	data, err := i.Get(ctx, file.String())
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello world"), data)
+
+	_, err = i.Get(ctx, dir.String())
+	require.ErrorContains(t, err, "is not a file")
+
+	// the node swaps a leaf
+	node.blocks[world.String()] = []byte("there")
+	_, err = i.Get(ctx, file.String())
+	require.ErrorIs(t, err, ipfs.ErrCIDMismatch)
+
+	// the node swaps a single block
+	node.blocks[hello.String()] = []byte("bye")
+	_, err = i.Get(ctx, hello.String())
+	require.ErrorIs(t, err, ipfs.ErrCIDMismatch)
+}
+
+func TestIpfsGetInvalidBlockSizes(t *testing.T) {
+	hello := rawBlockCid(t, []byte("hello "))
+	world := rawBlockCid(t, []byte("world"))
+	file, fileBlock := newFileNode(t, 2, []cid.Cid{hello, world}, []uint64{6, 6})
+
+	node := &fakeIpfsNode{
+		blocks: map[string][]byte{
+			hello.String(): []byte("hello "),
+			world.String(): []byte("world"),
+			file.String():  fileBlock,
+		},
+	}
+
+	_, err := newTestIpfs(t, node, 100).Get(context.Background(), file.String())
+	require.ErrorContains(t, err, "block size 6, got 5")
+}
+
+func TestIpfsAddVerifiesCid(t *testing.T) {
+	data := []byte("hello world")
+	node := &fakeIpfsNode{addedCid: rawBlockCid(t, data).String()}
+	i := newTestIpfs(t, node, 100)
+	ctx := context.Background()
+
+	c, err := i.Add(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, node.addedCid, c)
+
+	// the CID of other data
+	node.addedCid = rawBlockCid(t, []byte("bye")).String()
+	_, err = i.Add(ctx, data)
+	require.ErrorIs(t, err, ipfs.ErrCIDMismatch)
+
+	// the CID of a file with multiple blocks is verified by fetching the blocks
+	hello := rawBlockCid(t, []byte("hello "))
+	world := rawBlockCid(t, []byte("world"))
+	file, fileBlock := newFileNode(t, 2, []cid.Cid{hello, world}, []uint64{6, 5})
+	node.blocks = map[string][]byte{
+		hello.String(): []byte("hello "),
+		world.String(): []byte("world"),
+		file.String():  fileBlock,
+	}
+	node.addedCid = file.String()
+	c, err = i.Add(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, file.String(), c)
+
+	_, err = i.Add(ctx, []byte("hello there"))
+	require.ErrorIs(t, err, ipfs.ErrCIDMismatch)
+}
@@ -2,50 +2,124 @@ package ipfs
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/ipfs/go-cid"
 	shell "github.com/ipfs/go-ipfs-api"
+	files "github.com/ipfs/go-ipfs-files"
+	"github.com/medibloc/panacea-doracle/config"
 	log "github.com/sirupsen/logrus"
 )
 
+// ErrTooLarge is returned if the data in IPFS is larger than the maximum size.
+var ErrTooLarge = errors.New("data too large")
+
 type Ipfs struct {
-	sh *shell.Shell
+	sh      *shell.Shell
+	timeout time.Duration
+	maxSize int64
 }
 
 // NewIpfs generates an ipfs node with ipfs url.
-func NewIpfs(url string) *Ipfs {
-	newShell := shell.NewShell(url)
+// Each request is limited by the timeout in the config, and data larger than 'ipfs-max-size' is not fetched.
+func NewIpfs(conf *config.Config) *Ipfs {
+	newShell := shell.NewShell(conf.Ipfs.IpfsNodeAddr)
 
 	log.Info("successfully connect to IPFS node")
 
 	return &Ipfs{
-		sh: newShell,
+		sh:      newShell,
+		timeout: conf.Ipfs.Timeout,
+		maxSize: conf.Content.IpfsMaxSize,
 	}
 }
 
 // Add method adds a data and returns a CID.
-func (i *Ipfs) Add(data []byte) (string, error) {
-	reader := bytes.NewReader(data)
+// The data is added as CIDv1 with raw leaves, and the returned CID is checked against the data,
+// so that the IPFS node can't return a CID of other data.
+func (i *Ipfs) Add(ctx context.Context, data []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.timeout)
+	defer cancel()
+
+	fileReader := files.NewMultiFileReader(
+		files.NewSliceDirectory([]files.DirEntry{files.FileEntry("", files.NewBytesFile(data))}),
+		true,
+	)
 
-	cid, err := i.sh.Add(reader)
+	var out struct {
+		Hash string
+	}
+	err := i.sh.Request("add").
+		Option("cid-version", 1).
+		Option("raw-leaves", true).
+		Body(fileReader).
+		Exec(ctx, &out)
 	if err != nil {
 		return "", err
 	}
 
-	return cid, nil
+	if err := i.verifyAdded(ctx, out.Hash, data); err != nil {
+		return "", err
+	}
+	return out.Hash, nil
+}
+
+// verifyAdded checks that the CID returned by the IPFS node is the CID of the data.
+// The CID of a single raw block is computed locally. Otherwise, the DAG is fetched and verified.
+func (i *Ipfs) verifyAdded(ctx context.Context, cidStr string, data []byte) error {
+	c, err := cid.Decode(cidStr)
+	if err != nil {
+		return fmt.Errorf("invalid CID %s: %w", cidStr, err)
+	}
+
+	if c.Type() == cid.Raw {
+		sum, err := c.Prefix().Sum(data)
+		if err != nil {
+			return fmt.Errorf("failed to compute CID of data: %w", err)
+		}
+		if !sum.Equals(c) {
+			return fmt.Errorf("%w: added %s, got %s", ErrCIDMismatch, c, sum)
+		}
+		return nil
+	}
+
+	added, err := i.getVerified(ctx, c)
+	if err != nil {
+		return fmt.Errorf("failed to verify added %s: %w", c, err)
+	}
+	if !bytes.Equal(added, data) {
+		return fmt.Errorf("%w: added %s", ErrCIDMismatch, c)
+	}
+	return nil
 }
 
 // Get method gets a data and returns a bytes of Deal.
-func (i *Ipfs) Get(cid string) ([]byte, error) {
-	data, err := i.sh.Cat(cid)
+// The size of the data is checked by 'files/stat' before downloading,
+// and the download is aborted as soon as the data exceeds the maximum size.
+// The data is fetched block by block, and each block is checked against its CID locally.
+func (i *Ipfs) Get(ctx context.Context, cidStr string) ([]byte, error) {
+	c, err := cid.Decode(cidStr)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid CID %s: %w", cidStr, err)
 	}
 
-	buf := new(bytes.Buffer)
-	_, err = buf.ReadFrom(data)
+	ctx, cancel := context.WithTimeout(ctx, i.timeout)
+	defer cancel()
+
+	stat, err := i.sh.FilesStat(ctx, "/ipfs/"+c.String())
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to stat %s: %w", c, err)
+	}
+	if stat.Type != "file" {
+		return nil, fmt.Errorf("%s is not a file but a %s", c, stat.Type)
+	}
+	if stat.Size > uint64(i.maxSize) {
+		return nil, fmt.Errorf("%w: %s has %d bytes, limit %d", ErrTooLarge, c, stat.Size, i.maxSize)
 	}
 
-	return buf.Bytes(), nil
+	// the stat is not trusted. the size is checked again while fetching blocks.
+	return i.getVerified(ctx, c)
 }
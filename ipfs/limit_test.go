@@ -0,0 +1,105 @@
+package ipfs_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/medibloc/panacea-doracle/config"
+	"github.com/medibloc/panacea-doracle/ipfs"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIpfsNode serves 'files/stat', 'block/get' and 'add' of the IPFS HTTP API with the blocks in memory.
+type fakeIpfsNode struct {
+	blocks map[string][]byte
+	// statSizes overrides the size of files in 'files/stat'
+	statSizes map[string]uint64
+	// addedCid is returned by 'add'
+	addedCid string
+}
+
+func (n *fakeIpfsNode) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	arg := req.URL.Query().Get("arg")
+	switch req.URL.Path {
+	case "/api/v0/files/stat":
+		cid := strings.TrimPrefix(arg, "/ipfs/")
+		if cid == slowCid {
+			time.Sleep(time.Second)
+		}
+		size, ok := n.statSizes[cid]
+		if !ok {
+			size = uint64(len(n.blocks[cid]))
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"Type": "file", "Size": size})
+	case "/api/v0/block/get":
+		block, ok := n.blocks[arg]
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"Message": "block not found", "Code": 0})
+			return
+		}
+		_, _ = w.Write(block)
+	case "/api/v0/add":
+		_ = json.NewEncoder(w).Encode(map[string]string{"Hash": n.addedCid})
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+const slowCid = "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"
+
+func newTestIpfs(t *testing.T, node *fakeIpfsNode, maxSize int64) *ipfs.Ipfs {
+	server := httptest.NewServer(node)
+	t.Cleanup(server.Close)
+
+	conf := config.DefaultConfig()
+	conf.Ipfs.IpfsNodeAddr = server.Listener.Addr().String()
+	conf.Ipfs.Timeout = 500 * time.Millisecond
+	conf.Content.IpfsMaxSize = maxSize
+	return ipfs.NewIpfs(conf)
+}
+
+func TestIpfsGetWithLimit(t *testing.T) {
+	small := rawBlockCid(t, []byte("hello"))
+	large := rawBlockCid(t, []byte("hello world"))
+	node := &fakeIpfsNode{
+		blocks: map[string][]byte{
+			small.String(): []byte("hello"),
+			large.String(): []byte("hello world"),
+		},
+		statSizes: map[string]uint64{},
+	}
+	i := newTestIpfs(t, node, 10)
+	ctx := context.Background()
+
+	data, err := i.Get(ctx, small.String())
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), data)
+
+	// rejected by the stat
+	_, err = i.Get(ctx, large.String())
+	require.ErrorIs(t, err, ipfs.ErrTooLarge)
+	require.ErrorContains(t, err, "has 11 bytes")
+
+	// rejected while fetching blocks, because the stat is wrong
+	node.statSizes[large.String()] = 5
+	_, err = i.Get(ctx, large.String())
+	require.ErrorIs(t, err, ipfs.ErrTooLarge)
+	require.ErrorContains(t, err, "more than 10 bytes")
+
+	_, err = i.Get(ctx, slowCid)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = i.Get(canceledCtx, small.String())
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = i.Get(ctx, "invalid-cid")
+	require.ErrorContains(t, err, "invalid CID")
+}
@@ -1,9 +1,41 @@
 package panacea
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
 
 var (
 	ErrEmptyKey             = fmt.Errorf("empty key")
 	ErrEmptyValue           = fmt.Errorf("empty value")
 	ErrNegativeOrZeroHeight = fmt.Errorf("negative or zero height")
+	ErrNotFound             = fmt.Errorf("not found")
+	ErrHeightPruned         = fmt.Errorf("height has been pruned")
+
+	ErrLightClientAttackDetected = fmt.Errorf("light client attack detected")
 )
+
+// prunedHeightLogs are the logs of the store query at a pruned height.
+// The state of the height is not found by the multistore, or the IAVL store returns no proof.
+var prunedHeightLogs = []string{
+	"failed to load state at height",
+	"ensure height has not been pruned",
+}
+
+// isHeightPrunedResponse returns true if the ABCI query failed because the state at the height has been pruned.
+// The Cosmos SDK returns ErrInvalidRequest for a pruned height, but also for other invalid requests,
+// so the log is checked too.
+func isHeightPrunedResponse(resp abci.ResponseQuery) bool {
+	if resp.Codespace != sdkerrors.ErrInvalidRequest.Codespace() || resp.Code != sdkerrors.ErrInvalidRequest.ABCICode() {
+		return false
+	}
+	for _, log := range prunedHeightLogs {
+		if strings.Contains(resp.Log, log) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package panacea
+
+import (
+	"testing"
+
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestIsHeightPrunedResponse(t *testing.T) {
+	for _, tc := range []struct {
+		err    error
+		pruned bool
+	}{
+		{sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "proof is unexpectedly empty; ensure height has not been pruned"), true},
+		{sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "failed to load state at height %d; %s (latest height: %d)", 5, "version does not exist", 10), true},
+		{sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "cannot query with proof when height <= 1; please provide a valid height"), false},
+		{sdkerrors.Wrap(sdkerrors.ErrUnknownRequest, "ensure height has not been pruned"), false},
+	} {
+		resp := sdkerrors.QueryResult(tc.err)
+		require.Equal(t, tc.pruned, isHeightPrunedResponse(resp), resp.Log)
+	}
+
+	require.False(t, isHeightPrunedResponse(abci.ResponseQuery{Code: 1, Log: "ensure height has not been pruned"}))
+}
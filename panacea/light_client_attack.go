@@ -0,0 +1,150 @@
+package panacea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	"github.com/tendermint/tendermint/light"
+	"github.com/tendermint/tendermint/light/provider"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+const evidenceDirName = "light-client-evidence"
+
+// LightClientAttackStatus shows whether the light client has detected an attack or a divergence between the primary and witnesses.
+type LightClientAttackStatus struct {
+	Detected      bool       `json:"detected"`
+	DetectedAt    *time.Time `json:"detected_at,omitempty"`
+	Reason        string     `json:"reason,omitempty"`
+	EvidenceFiles []string   `json:"evidence_files,omitempty"`
+}
+
+// attackMonitor keeps the attack status of the light client.
+// Once an attack is detected, it stays detected until the process is restarted,
+// so that the oracle doesn't use any queried data (fail closed).
+type attackMonitor struct {
+	mutex          sync.RWMutex
+	status         LightClientAttackStatus
+	evidenceDir    string
+	submitEvidence bool
+}
+
+func newAttackMonitor(evidenceDir string, submitEvidence bool) *attackMonitor {
+	return &attackMonitor{
+		evidenceDir:    evidenceDir,
+		submitEvidence: submitEvidence,
+	}
+}
+
+// Status returns a copy of the current attack status.
+func (m *attackMonitor) Status() LightClientAttackStatus {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	status := m.status
+	status.EvidenceFiles = append([]string(nil), m.status.EvidenceFiles...)
+	return status
+}
+
+// Err returns ErrLightClientAttackDetected if an attack has been detected.
+func (m *attackMonitor) Err() error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if !m.status.Detected {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrLightClientAttackDetected, m.status.Reason)
+}
+
+// Check marks the attack as detected, if err is returned by the light client because of an attack.
+func (m *attackMonitor) Check(err error) {
+	if errors.Is(err, light.ErrLightClientAttack) {
+		m.detect("the light client detected an attack: divergent headers between the primary and witnesses")
+	}
+}
+
+func (m *attackMonitor) detect(reason string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.status.Detected {
+		return
+	}
+
+	m.status.Detected = true
+	now := time.Now()
+	m.status.DetectedAt = &now
+	m.status.Reason = reason
+	log.Errorf("%s. the oracle stops using queried data until the light client is checked and the oracle is restarted", reason)
+}
+
+// recordEvidence writes the evidence to a local file and marks the attack as detected.
+func (m *attackMonitor) recordEvidence(ev tmtypes.Evidence) {
+	m.detect(fmt.Sprintf("evidence of a light client attack was found at height(%d)", ev.Height()))
+
+	path, err := m.writeEvidence(ev)
+	if err != nil {
+		log.Errorf("failed to write the evidence of light client attack: %v", err)
+		return
+	}
+	log.Errorf("the evidence of light client attack is written to %s", path)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.status.EvidenceFiles = append(m.status.EvidenceFiles, path)
+}
+
+func (m *attackMonitor) writeEvidence(ev tmtypes.Evidence) (string, error) {
+	evidenceBz, err := tmjson.MarshalIndent(ev, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal evidence: %w", err)
+	}
+
+	if err := os.MkdirAll(m.evidenceDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create evidence dir: %w", err)
+	}
+
+	path := filepath.Join(m.evidenceDir, fmt.Sprintf("evidence-%d-%d.json", ev.Height(), time.Now().UnixNano()))
+	if err := os.WriteFile(path, evidenceBz, 0600); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return path, nil
+}
+
+var _ provider.Provider = (*evidenceRecordingProvider)(nil)
+
+// evidenceRecordingProvider is a provider.Provider which records all evidence reported by the light client,
+// and submits them to the chain only if the submission is enabled.
+type evidenceRecordingProvider struct {
+	provider.Provider
+	monitor *attackMonitor
+}
+
+func newEvidenceRecordingProvider(pv provider.Provider, monitor *attackMonitor) *evidenceRecordingProvider {
+	return &evidenceRecordingProvider{
+		Provider: pv,
+		monitor:  monitor,
+	}
+}
+
+func (p *evidenceRecordingProvider) ReportEvidence(ctx context.Context, ev tmtypes.Evidence) error {
+	p.monitor.recordEvidence(ev)
+
+	if !p.monitor.submitEvidence {
+		return nil
+	}
+	return p.Provider.ReportEvidence(ctx, ev)
+}
+
+func (p *evidenceRecordingProvider) String() string {
+	return fmt.Sprintf("%v", p.Provider)
+}
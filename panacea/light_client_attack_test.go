@@ -0,0 +1,66 @@
+package panacea
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/light"
+	"github.com/tendermint/tendermint/light/provider"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+type mockProvider struct {
+	provider.Provider
+	reported []tmtypes.Evidence
+}
+
+func (p *mockProvider) ReportEvidence(_ context.Context, ev tmtypes.Evidence) error {
+	p.reported = append(p.reported, ev)
+	return nil
+}
+
+func TestAttackMonitorCheck(t *testing.T) {
+	monitor := newAttackMonitor(t.TempDir(), false)
+
+	monitor.Check(nil)
+	require.NoError(t, monitor.Err())
+	require.False(t, monitor.Status().Detected)
+
+	monitor.Check(light.ErrLightClientAttack)
+	require.ErrorIs(t, monitor.Err(), ErrLightClientAttackDetected)
+	require.True(t, monitor.Status().Detected)
+}
+
+func TestEvidenceRecordingProvider(t *testing.T) {
+	testCases := []struct {
+		name           string
+		submitEvidence bool
+		expectedReport int
+	}{
+		{"submit evidence", true, 1},
+		{"do not submit evidence", false, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			monitor := newAttackMonitor(t.TempDir(), tc.submitEvidence)
+			mock := &mockProvider{}
+			pv := newEvidenceRecordingProvider(mock, monitor)
+
+			err := pv.ReportEvidence(context.Background(), &tmtypes.LightClientAttackEvidence{CommonHeight: 10})
+			require.NoError(t, err)
+			require.Len(t, mock.reported, tc.expectedReport)
+
+			status := monitor.Status()
+			require.True(t, status.Detected)
+			require.Len(t, status.EvidenceFiles, 1)
+			require.FileExists(t, status.EvidenceFiles[0])
+
+			info, err := os.Stat(status.EvidenceFiles[0])
+			require.NoError(t, err)
+			require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		})
+	}
+}
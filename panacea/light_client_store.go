@@ -0,0 +1,419 @@
+package panacea
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/medibloc/panacea-doracle/config"
+	"github.com/medibloc/panacea-doracle/store/sgxdb"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
+	tmmath "github.com/tendermint/tendermint/libs/math"
+	"github.com/tendermint/tendermint/light"
+	"github.com/tendermint/tendermint/light/store"
+	dbs "github.com/tendermint/tendermint/light/store/db"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+)
+
+const (
+	lightClientDBName          = "light-client"
+	lightClientDataKeyFileName = "light-client-data-key.sealed"
+)
+
+// LightClientStoreInfo is a summary of light blocks stored in the light client store.
+type LightClientStoreInfo struct {
+	ChainID            string           `json:"chain_id"`
+	LastTrustedHeight  int64            `json:"last_trusted_height"`
+	LastTrustedHash    tmbytes.HexBytes `json:"last_trusted_hash"`
+	LastTrustedTime    time.Time        `json:"last_trusted_time"`
+	FirstTrustedHeight int64            `json:"first_trusted_height"`
+	Size               uint16           `json:"size"`
+	PrimaryAddr        string           `json:"primary_addr"`
+	WitnessAddrs       []string         `json:"witness_addrs"`
+}
+
+// LightClientSnapshot contains all light blocks of the light client store.
+// Each light block is encoded as a tendermint.types.LightBlock protobuf message.
+type LightClientSnapshot struct {
+	ChainID     string   `json:"chain_id"`
+	LightBlocks [][]byte `json:"light_blocks"`
+}
+
+// OpenLightClientDB opens the sealed DB used by the light client, using the DB backend in the config.
+// Values are encrypted by the data key which is sealed in the data dir. If the data key doesn't exist, it is generated.
+func OpenLightClientDB(conf *config.Config) (*sgxdb.SgxDB, error) {
+	db, err := openLightClientRawDB(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	keyring, err := sgxdb.LoadOrCreateDataKeyring(lightClientDataKeyPath(conf), sgxdb.EnclaveSealer(), db)
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to load data key of light client DB: %w", err)
+	}
+
+	return wrapLightClientDB(db, keyring)
+}
+
+// openLightClientRawDB opens the light client DB without the sealing layer.
+func openLightClientRawDB(conf *config.Config) (dbm.DB, error) {
+	db, err := dbm.NewDB(lightClientDBName, dbm.BackendType(conf.DBBackend), conf.AbsDataDirPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s db: %w", conf.DBBackend, err)
+	}
+	return db, nil
+}
+
+func wrapLightClientDB(db dbm.DB, keyring *sgxdb.DataKeyring) (*sgxdb.SgxDB, error) {
+	// values written before the data key was introduced are sealed by the enclave directly
+	sealer, err := sgxdb.NewEnvelopeSealer(keyring, sgxdb.EnclaveSealer())
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return sgxdb.NewSgxDBWithSealer(db, sealer), nil
+}
+
+func lightClientDataKeyPath(conf *config.Config) string {
+	return filepath.Join(conf.AbsDataDirPath(), lightClientDataKeyFileName)
+}
+
+// RotateLightClientDataKey generates a new data key, and re-encrypts all values in the light client DB with it.
+// The old data keys are removed only after all values are re-encrypted,
+// so that no value becomes unreadable even if the rotation is interrupted.
+// It returns the new key version and the number of values re-encrypted.
+func RotateLightClientDataKey(conf *config.Config) (uint32, int, error) {
+	keyringPath := lightClientDataKeyPath(conf)
+
+	rawDB, err := openLightClientRawDB(conf)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to open light client DB: %w", err)
+	}
+
+	keyring, err := sgxdb.LoadOrCreateDataKeyring(keyringPath, sgxdb.EnclaveSealer(), rawDB)
+	if err != nil {
+		_ = rawDB.Close()
+		return 0, 0, fmt.Errorf("failed to load data key of light client DB: %w", err)
+	}
+
+	if err := keyring.Rotate(); err != nil {
+		_ = rawDB.Close()
+		return 0, 0, err
+	}
+	if err := keyring.Save(keyringPath, sgxdb.EnclaveSealer()); err != nil {
+		_ = rawDB.Close()
+		return 0, 0, err
+	}
+
+	db, err := wrapLightClientDB(rawDB, keyring)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to open light client DB: %w", err)
+	}
+	defer db.Close()
+
+	count, err := sgxdb.Reencrypt(db)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to re-encrypt light client DB: %w", err)
+	}
+
+	keyring.PruneOldVersions()
+	if err := keyring.Save(keyringPath, sgxdb.EnclaveSealer()); err != nil {
+		return 0, 0, err
+	}
+
+	return keyring.CurrentVersion, count, nil
+}
+
+// MigrateLightClientDataKey re-seals the data key of the light client DB with the seal policy.
+// Values sealed before the data key was introduced are re-encrypted by the data key,
+// because they are sealed with the unique key which cannot be unsealed by other binaries.
+// It returns the number of values re-encrypted. If the data key doesn't exist, an error wrapping os.ErrNotExist is returned.
+func MigrateLightClientDataKey(conf *config.Config, policy string) (int, error) {
+	keyringPath := lightClientDataKeyPath(conf)
+
+	keyring, err := sgxdb.LoadDataKeyring(keyringPath, sgxdb.EnclaveSealer())
+	if err != nil {
+		return 0, fmt.Errorf("failed to load data key of light client DB: %w", err)
+	}
+
+	rawDB, err := openLightClientRawDB(conf)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open light client DB: %w", err)
+	}
+	db, err := wrapLightClientDB(rawDB, keyring)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open light client DB: %w", err)
+	}
+	defer db.Close()
+
+	count, err := sgxdb.Reencrypt(db)
+	if err != nil {
+		return 0, fmt.Errorf("failed to re-encrypt light client DB: %w", err)
+	}
+
+	if err := keyring.Save(keyringPath, sgxdb.EnclaveSealerWithPolicy(policy)); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetLightClientStoreInfo returns the summary of the light client store without connecting to any node.
+func GetLightClientStoreInfo(conf *config.Config, db dbm.DB) (*LightClientStoreInfo, error) {
+	lightStore := dbs.New(db, conf.Panacea.ChainID)
+
+	lastBlock, err := lastLightBlock(lightStore)
+	if err != nil {
+		return nil, err
+	}
+
+	firstHeight, err := lightStore.FirstLightBlockHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	return &LightClientStoreInfo{
+		ChainID:            conf.Panacea.ChainID,
+		LastTrustedHeight:  lastBlock.Height,
+		LastTrustedHash:    lastBlock.Hash(),
+		LastTrustedTime:    lastBlock.Time,
+		FirstTrustedHeight: firstHeight,
+		Size:               lightStore.Size(),
+		PrimaryAddr:        conf.Panacea.LightClientPrimaryAddr,
+		WitnessAddrs:       conf.Panacea.LightClientWitnessAddrs,
+	}, nil
+}
+
+// ResetLightClientStore deletes all light blocks from the light client store.
+func ResetLightClientStore(conf *config.Config, db dbm.DB) error {
+	lightStore := dbs.New(db, conf.Panacea.ChainID)
+
+	for {
+		height, err := lightStore.LastLightBlockHeight()
+		if err != nil {
+			return err
+		}
+		if height <= 0 {
+			return nil
+		}
+		if err := lightStore.DeleteLightBlock(height); err != nil {
+			return fmt.Errorf("failed to delete light block at height(%d): %w", height, err)
+		}
+	}
+}
+
+// VerifyTrustedBlock verifies the trusted block with the primary and witnesses in the config,
+// and returns the verified light block. The light client of the verification uses a memory DB,
+// so that the light client store is not modified even if the verification fails.
+func VerifyTrustedBlock(ctx context.Context, conf *config.Config, info *TrustedBlockInfo) (*tmtypes.LightBlock, error) {
+	memDB := dbm.NewMemDB()
+	queryClient, err := NewQueryClientWithDB(ctx, conf, info, memDB)
+	if err != nil {
+		return nil, err
+	}
+	defer queryClient.Close()
+
+	return dbs.New(memDB, conf.Panacea.ChainID).LightBlock(info.TrustedBlockHeight)
+}
+
+// ResetLightClientStoreTo replaces all light blocks in the light client store with the light block,
+// which must be verified by VerifyTrustedBlock.
+func ResetLightClientStoreTo(conf *config.Config, db dbm.DB, lightBlock *tmtypes.LightBlock) error {
+	if err := ResetLightClientStore(conf, db); err != nil {
+		return err
+	}
+	if err := dbs.New(db, conf.Panacea.ChainID).SaveLightBlock(lightBlock); err != nil {
+		return fmt.Errorf("failed to save light block at height(%d): %w", lightBlock.Height, err)
+	}
+	return nil
+}
+
+// RollbackLightClientStore deletes all light blocks above the height.
+// Because the light client doesn't store every block, the latest block at or below the height becomes the last trusted block.
+// It returns the light block which becomes the last trusted block.
+func RollbackLightClientStore(conf *config.Config, db dbm.DB, height int64) (*tmtypes.LightBlock, error) {
+	lightStore := dbs.New(db, conf.Panacea.ChainID)
+
+	target, err := lightStore.LightBlock(height)
+	if errors.Is(err, store.ErrLightBlockNotFound) {
+		target, err = lightStore.LightBlockBefore(height)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("no light block at or below height(%d): %w", height, err)
+	}
+
+	for {
+		lastHeight, err := lightStore.LastLightBlockHeight()
+		if err != nil {
+			return nil, err
+		}
+		if lastHeight <= target.Height {
+			return target, nil
+		}
+		if err := lightStore.DeleteLightBlock(lastHeight); err != nil {
+			return nil, fmt.Errorf("failed to delete light block at height(%d): %w", lastHeight, err)
+		}
+	}
+}
+
+// ExportLightClientStore exports all light blocks of the light client store.
+func ExportLightClientStore(conf *config.Config, db dbm.DB) (*LightClientSnapshot, error) {
+	lightStore := dbs.New(db, conf.Panacea.ChainID)
+
+	lastBlock, err := lastLightBlock(lightStore)
+	if err != nil {
+		return nil, err
+	}
+
+	// collect light blocks in descending order, and then reverse them
+	var lightBlocks [][]byte
+	for lightBlock := lastBlock; ; {
+		lightBlockBz, err := marshalLightBlock(lightBlock)
+		if err != nil {
+			return nil, err
+		}
+		lightBlocks = append(lightBlocks, lightBlockBz)
+
+		lightBlock, err = lightStore.LightBlockBefore(lightBlock.Height)
+		if errors.Is(err, store.ErrLightBlockNotFound) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	for i, j := 0, len(lightBlocks)-1; i < j; i, j = i+1, j-1 {
+		lightBlocks[i], lightBlocks[j] = lightBlocks[j], lightBlocks[i]
+	}
+
+	return &LightClientSnapshot{
+		ChainID:     conf.Panacea.ChainID,
+		LightBlocks: lightBlocks,
+	}, nil
+}
+
+// ImportLightClientStore replaces all light blocks in the light client store with the light blocks in the snapshot.
+// The light blocks are verified one by one from the first one, and the last one is verified with the primary and witnesses
+// in the config, like VerifyTrustedBlock. So, a snapshot which doesn't end at a block of the chain is rejected.
+// It returns the last light block imported.
+func ImportLightClientStore(ctx context.Context, conf *config.Config, db dbm.DB, snapshot *LightClientSnapshot) (*tmtypes.LightBlock, error) {
+	lightBlocks, err := ValidateLightClientSnapshot(conf, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	last := lightBlocks[len(lightBlocks)-1]
+	verified, err := VerifyTrustedBlock(ctx, conf, &TrustedBlockInfo{TrustedBlockHeight: last.Height, TrustedBlockHash: last.Hash()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify the last light block at height(%d) with the primary and witnesses: %w", last.Height, err)
+	}
+	if !bytes.Equal(verified.Hash(), last.Hash()) {
+		return nil, fmt.Errorf("the last light block at height(%d) is different from the one of the primary", last.Height)
+	}
+
+	if err := saveLightBlocks(conf, db, lightBlocks); err != nil {
+		return nil, err
+	}
+	return last, nil
+}
+
+// ValidateLightClientSnapshot validates the light blocks in the snapshot, and returns them.
+// The first light block is trusted as it is, and each of the others is verified by its previous light block.
+func ValidateLightClientSnapshot(conf *config.Config, snapshot *LightClientSnapshot) ([]*tmtypes.LightBlock, error) {
+	if snapshot.ChainID != conf.Panacea.ChainID {
+		return nil, fmt.Errorf("chain ID of the snapshot(%s) is different from the config(%s)", snapshot.ChainID, conf.Panacea.ChainID)
+	}
+	if len(snapshot.LightBlocks) == 0 {
+		return nil, errors.New("no light block in the snapshot")
+	}
+
+	trustLevel, err := tmmath.ParseFraction(conf.Panacea.LightClientTrustLevel)
+	if err != nil {
+		return nil, fmt.Errorf("invalid light client trust level: %w", err)
+	}
+
+	lightBlocks := make([]*tmtypes.LightBlock, 0, len(snapshot.LightBlocks))
+	for i, lightBlockBz := range snapshot.LightBlocks {
+		lightBlock, err := unmarshalLightBlock(lightBlockBz)
+		if err != nil {
+			return nil, err
+		}
+		if err := lightBlock.ValidateBasic(conf.Panacea.ChainID); err != nil {
+			return nil, fmt.Errorf("invalid light block at height(%d): %w", lightBlock.Height, err)
+		}
+
+		if i > 0 {
+			trusted := lightBlocks[i-1]
+			// verify as of the time when the light block was produced, because old light blocks may be expired now.
+			err := light.Verify(
+				trusted.SignedHeader,
+				trusted.ValidatorSet,
+				lightBlock.SignedHeader,
+				lightBlock.ValidatorSet,
+				conf.Panacea.LightClientTrustingPeriod,
+				lightBlock.Time,
+				conf.Panacea.LightClientMaxClockDrift,
+				trustLevel,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to verify light block at height(%d): %w", lightBlock.Height, err)
+			}
+		}
+
+		lightBlocks = append(lightBlocks, lightBlock)
+	}
+
+	return lightBlocks, nil
+}
+
+// saveLightBlocks replaces all light blocks in the light client store with the light blocks.
+func saveLightBlocks(conf *config.Config, db dbm.DB, lightBlocks []*tmtypes.LightBlock) error {
+	if err := ResetLightClientStore(conf, db); err != nil {
+		return fmt.Errorf("failed to reset light client store: %w", err)
+	}
+
+	lightStore := dbs.New(db, conf.Panacea.ChainID)
+	for _, lightBlock := range lightBlocks {
+		if err := lightStore.SaveLightBlock(lightBlock); err != nil {
+			return fmt.Errorf("failed to save light block at height(%d): %w", lightBlock.Height, err)
+		}
+	}
+	return nil
+}
+
+func lastLightBlock(lightStore store.Store) (*tmtypes.LightBlock, error) {
+	lastHeight, err := lightStore.LastLightBlockHeight()
+	if err != nil {
+		return nil, err
+	}
+	if lastHeight <= 0 {
+		return nil, errors.New("no light block in the light client store")
+	}
+
+	return lightStore.LightBlock(lastHeight)
+}
+
+func marshalLightBlock(lightBlock *tmtypes.LightBlock) ([]byte, error) {
+	lightBlockPb, err := lightBlock.ToProto()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert light block to proto: %w", err)
+	}
+
+	return lightBlockPb.Marshal()
+}
+
+func unmarshalLightBlock(lightBlockBz []byte) (*tmtypes.LightBlock, error) {
+	var lightBlockPb tmproto.LightBlock
+	if err := lightBlockPb.Unmarshal(lightBlockBz); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal light block: %w", err)
+	}
+
+	return tmtypes.LightBlockFromProto(&lightBlockPb)
+}
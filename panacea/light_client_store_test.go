@@ -0,0 +1,143 @@
+package panacea
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/medibloc/panacea-doracle/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	dbs "github.com/tendermint/tendermint/light/store/db"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmversion "github.com/tendermint/tendermint/proto/tendermint/version"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"github.com/tendermint/tendermint/version"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// makeLightBlocks makes light blocks signed by the same validator at the heights.
+func makeLightBlocks(t *testing.T, chainID string, heights ...int64) []*tmtypes.LightBlock {
+	vals, privVals := tmtypes.RandValidatorSet(1, 10)
+	baseTime := time.Now().Add(-time.Hour)
+
+	var lightBlocks []*tmtypes.LightBlock
+	for _, height := range heights {
+		header := &tmtypes.Header{
+			Version:            tmversion.Consensus{Block: version.BlockProtocol},
+			ChainID:            chainID,
+			Height:             height,
+			Time:               baseTime.Add(time.Duration(height) * time.Second),
+			ValidatorsHash:     vals.Hash(),
+			NextValidatorsHash: vals.Hash(),
+			ProposerAddress:    vals.Proposer.Address,
+		}
+		blockID := tmtypes.BlockID{
+			Hash:          header.Hash(),
+			PartSetHeader: tmtypes.PartSetHeader{Total: 1, Hash: tmhash.Sum([]byte("parts"))},
+		}
+
+		voteSet := tmtypes.NewVoteSet(chainID, height, 0, tmproto.PrecommitType, vals)
+		commit, err := tmtypes.MakeCommit(blockID, height, 0, voteSet, privVals, header.Time)
+		require.NoError(t, err)
+
+		lightBlocks = append(lightBlocks, &tmtypes.LightBlock{
+			SignedHeader: &tmtypes.SignedHeader{Header: header, Commit: commit},
+			ValidatorSet: vals,
+		})
+	}
+
+	return lightBlocks
+}
+
+func TestLightClientStore(t *testing.T) {
+	conf := config.DefaultConfig()
+	db := dbm.NewMemDB()
+
+	lightStore := dbs.New(db, conf.Panacea.ChainID)
+	for _, lightBlock := range makeLightBlocks(t, conf.Panacea.ChainID, 1, 5, 10) {
+		require.NoError(t, lightStore.SaveLightBlock(lightBlock))
+	}
+
+	info, err := GetLightClientStoreInfo(conf, db)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), info.LastTrustedHeight)
+	require.Equal(t, int64(1), info.FirstTrustedHeight)
+	require.Equal(t, uint16(3), info.Size)
+
+	snapshot, err := ExportLightClientStore(conf, db)
+	require.NoError(t, err)
+	require.Len(t, snapshot.LightBlocks, 3)
+
+	// roll back to the height where no light block is stored
+	lightBlock, err := RollbackLightClientStore(conf, db, 7)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), lightBlock.Height)
+
+	info, err = GetLightClientStoreInfo(conf, db)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), info.LastTrustedHeight)
+
+	require.NoError(t, ResetLightClientStore(conf, db))
+	_, err = GetLightClientStoreInfo(conf, db)
+	require.Error(t, err)
+
+	// the last light block can't be verified without the primary and witnesses
+	conf.Panacea.LightClientPrimaryAddr = "tcp://127.0.0.1:1"
+	conf.Panacea.LightClientWitnessAddrs = []string{"tcp://127.0.0.1:1"}
+	_, err = ImportLightClientStore(context.Background(), conf, db, snapshot)
+	require.ErrorContains(t, err, "failed to verify the last light block at height(10)")
+	_, err = GetLightClientStoreInfo(conf, db)
+	require.Error(t, err)
+
+	lightBlocks, err := ValidateLightClientSnapshot(conf, snapshot)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), lightBlocks[len(lightBlocks)-1].Height)
+	require.NoError(t, saveLightBlocks(conf, db, lightBlocks))
+
+	info, err = GetLightClientStoreInfo(conf, db)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), info.LastTrustedHeight)
+	require.Equal(t, uint16(3), info.Size)
+}
+
+func TestImportLightClientStoreInvalidSnapshot(t *testing.T) {
+	conf := config.DefaultConfig()
+
+	lightBlocks := makeLightBlocks(t, conf.Panacea.ChainID, 1, 5)
+	// the light block signed by other validators cannot be verified by the previous one
+	lightBlocks = append(lightBlocks, makeLightBlocks(t, conf.Panacea.ChainID, 10)...)
+
+	snapshot := &LightClientSnapshot{ChainID: conf.Panacea.ChainID}
+	for _, lightBlock := range lightBlocks {
+		lightBlockBz, err := marshalLightBlock(lightBlock)
+		require.NoError(t, err)
+		snapshot.LightBlocks = append(snapshot.LightBlocks, lightBlockBz)
+	}
+
+	_, err := ValidateLightClientSnapshot(conf, snapshot)
+	require.ErrorContains(t, err, "failed to verify light block at height(10)")
+
+	snapshot.ChainID = "other-chain"
+	_, err = ValidateLightClientSnapshot(conf, snapshot)
+	require.ErrorContains(t, err, "chain ID of the snapshot")
+}
+
+func TestResetLightClientStoreTo(t *testing.T) {
+	conf := config.DefaultConfig()
+	db := dbm.NewMemDB()
+
+	lightBlocks := makeLightBlocks(t, conf.Panacea.ChainID, 1, 5, 20)
+	lightStore := dbs.New(db, conf.Panacea.ChainID)
+	for _, lightBlock := range lightBlocks[:2] {
+		require.NoError(t, lightStore.SaveLightBlock(lightBlock))
+	}
+
+	require.NoError(t, ResetLightClientStoreTo(conf, db, lightBlocks[2]))
+
+	info, err := GetLightClientStoreInfo(conf, db)
+	require.NoError(t, err)
+	require.Equal(t, int64(20), info.LastTrustedHeight)
+	require.Equal(t, int64(20), info.FirstTrustedHeight)
+	require.Equal(t, uint16(1), info.Size)
+}
@@ -0,0 +1,109 @@
+package panacea
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+	paramstypes "github.com/cosmos/cosmos-sdk/x/params/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	oracletypes "github.com/medibloc/panacea-core/v2/x/oracle/types"
+)
+
+// GetParams gets all params in the ParamSet from the params subspace, and verifies them with light client and merkle proof.
+// All params are queried at the same trusted height, so that they are consistent with each other.
+//
+// This can be used for any module which has a params subspace.
+// The datadeal module of panacea-core doesn't have its params yet, so only oracle params are provided as a typed query.
+func (q QueryClient) GetParams(ctx context.Context, subspace string, ps paramstypes.ParamSet) error {
+	height, err := q.getLatestTrustedHeight(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, pair := range ps.ParamSetPairs() {
+		if err := q.getParamWithHeight(ctx, height, subspace, pair.Key, pair.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getParam gets a param from the params subspace at the latest trusted height.
+func (q QueryClient) getParam(ctx context.Context, subspace string, key []byte, ptr interface{}) error {
+	height, err := q.getLatestTrustedHeight(ctx)
+	if err != nil {
+		return err
+	}
+
+	return q.getParamWithHeight(ctx, height, subspace, key, ptr)
+}
+
+func (q QueryClient) getParamWithHeight(ctx context.Context, height int64, subspace string, key []byte, ptr interface{}) error {
+	paramKey := append(append([]byte(subspace), '/'), key...)
+
+	bz, err := q.getStoreDataWithHeight(ctx, height, paramstypes.StoreKey, paramKey)
+	if err != nil {
+		return fmt.Errorf("failed to get param %s: %w", paramKey, err)
+	}
+
+	// If you get a value from params, you should not use protoCodec, but use legacyAmino.
+	if err := q.aminoCdc.LegacyAmino.UnmarshalJSON(bz, ptr); err != nil {
+		return fmt.Errorf("failed to unmarshal param %s: %w", paramKey, err)
+	}
+
+	return nil
+}
+
+// GetOracleParams returns all params of the oracle module.
+func (q QueryClient) GetOracleParams() (*oracletypes.Params, error) {
+	var params oracletypes.Params
+	if err := q.GetParams(context.Background(), oracletypes.ModuleName, &params); err != nil {
+		return nil, err
+	}
+
+	return &params, nil
+}
+
+// GetOracleParamsPublicKey returns the oracle public key in the oracle params.
+func (q QueryClient) GetOracleParamsPublicKey() (*btcec.PublicKey, error) {
+	var pubKeyBase64 string
+	if err := q.getParam(context.Background(), oracletypes.ModuleName, oracletypes.KeyOraclePublicKey, &pubKeyBase64); err != nil {
+		return nil, err
+	}
+
+	pubKeyBz, err := base64.StdEncoding.DecodeString(pubKeyBase64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64 pubkey: %w", err)
+	}
+
+	return btcec.ParsePubKey(pubKeyBz, btcec.S256())
+}
+
+// GetOracleParamsPubKeyRemoteReport returns the remote report of the oracle public key in the oracle params.
+func (q QueryClient) GetOracleParamsPubKeyRemoteReport() ([]byte, error) {
+	var reportBase64 string
+	if err := q.getParam(context.Background(), oracletypes.ModuleName, oracletypes.KeyOraclePubKeyRemoteReport, &reportBase64); err != nil {
+		return nil, err
+	}
+
+	report, err := base64.StdEncoding.DecodeString(reportBase64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64 remote report: %w", err)
+	}
+
+	return report, nil
+}
+
+// GetStakingUnbondingTime returns the unbonding time in the staking params.
+func (q QueryClient) GetStakingUnbondingTime() (time.Duration, error) {
+	var unbondingTime time.Duration
+	if err := q.getParam(context.Background(), stakingtypes.ModuleName, stakingtypes.KeyUnbondingTime, &unbondingTime); err != nil {
+		return 0, err
+	}
+
+	return unbondingTime, nil
+}
@@ -1,30 +1,32 @@
 package panacea
 
 import (
+	"bytes"
 	"context"
-	"encoding/base64"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/btcsuite/btcd/btcec"
 	ics23 "github.com/confio/ics23/go"
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/codec/types"
 	"github.com/cosmos/cosmos-sdk/std"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/kv"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
-	paramstypes "github.com/cosmos/cosmos-sdk/x/params/types"
 	"github.com/cosmos/ibc-go/v2/modules/core/23-commitment/types"
 	datadealtypes "github.com/medibloc/panacea-core/v2/x/datadeal/types"
 	oracletypes "github.com/medibloc/panacea-core/v2/x/oracle/types"
 	"github.com/medibloc/panacea-doracle/config"
-	sgxdb "github.com/medibloc/panacea-doracle/store/sgxleveldb"
+	"github.com/medibloc/panacea-doracle/health"
 	log "github.com/sirupsen/logrus"
 	tmbytes "github.com/tendermint/tendermint/libs/bytes"
 	tmlog "github.com/tendermint/tendermint/libs/log"
+	tmmath "github.com/tendermint/tendermint/libs/math"
 	"github.com/tendermint/tendermint/light"
 	"github.com/tendermint/tendermint/light/provider"
 	tmhttp "github.com/tendermint/tendermint/light/provider/http"
@@ -36,10 +38,6 @@ import (
 	dbm "github.com/tendermint/tm-db"
 )
 
-const (
-	trustedPeriod = 2 * 365 * 24 * time.Hour
-)
-
 type TrustedBlockInfo struct {
 	TrustedBlockHeight int64
 	TrustedBlockHash   []byte
@@ -53,8 +51,22 @@ type QueryClient struct {
 	cdc         *codec.ProtoCodec
 	aminoCdc    *codec.AminoCodec
 	chainID     string
+
+	attackMonitor *attackMonitor
+	health        *health.Registry
+
+	// cancel stops the background refresh, and refreshDone is closed when the refresh is stopped.
+	cancel      context.CancelFunc
+	refreshDone chan struct{}
+	closeOnce   *sync.Once
 }
 
+// HealthComponentLightClientRefresh is the name of the background light client refresh in the health registry.
+const HealthComponentLightClientRefresh = "light_client_refresh"
+
+// maxPrefixQueryKeys is the maximum number of keys verified by GetStoreDataByPrefix.
+const maxPrefixQueryKeys = 1000
+
 // makeInterfaceRegistry
 func makeInterfaceRegistry() sdk.InterfaceRegistry {
 	interfaceRegistry := sdk.NewInterfaceRegistry()
@@ -74,7 +86,7 @@ func LoadQueryClient(ctx context.Context, config *config.Config) (*QueryClient,
 }
 
 func newQueryClientWithSgxLevelDB(ctx context.Context, config *config.Config, info *TrustedBlockInfo) (*QueryClient, error) {
-	db, err := sgxdb.NewSgxLevelDB("light-client", config.AbsDataDirPath())
+	db, err := OpenLightClientDB(config)
 	if err != nil {
 		return nil, err
 	}
@@ -92,10 +104,14 @@ func NewQueryClientWithDB(ctx context.Context, config *config.Config, info *Trus
 		return nil, err
 	}
 
-	pv, err := tmhttp.New(chainID, config.Panacea.LightClientPrimaryAddr)
+	// all evidence reported by the light client is recorded by the monitor
+	monitor := newAttackMonitor(filepath.Join(config.AbsDataDirPath(), evidenceDirName), config.Panacea.LightClientSubmitEvidence)
+
+	primary, err := tmhttp.New(chainID, config.Panacea.LightClientPrimaryAddr)
 	if err != nil {
 		return nil, err
 	}
+	pv := newEvidenceRecordingProvider(primary, monitor)
 
 	var pvs []provider.Provider
 	for _, witnessAddr := range config.Panacea.LightClientWitnessAddrs {
@@ -103,27 +119,30 @@ func NewQueryClientWithDB(ctx context.Context, config *config.Config, info *Trus
 		if err != nil {
 			return nil, err
 		}
-		pvs = append(pvs, witness)
+		pvs = append(pvs, newEvidenceRecordingProvider(witness, monitor))
 	}
 
 	store := dbs.New(db, chainID)
 
 	var lc *light.Client
-	logger := light.Logger(newTMLogger(config))
+	trustingPeriod := config.Panacea.LightClientTrustingPeriod
+	options, err := newLightClientOptions(config)
+	if err != nil {
+		return nil, err
+	}
 
 	if info == nil {
 		lc, err = light.NewClientFromTrustedStore(
 			chainID,
-			trustedPeriod,
+			trustingPeriod,
 			pv,
 			pvs,
 			store,
-			light.SkippingVerification(light.DefaultTrustLevel),
-			logger,
+			options...,
 		)
 	} else {
 		trustOptions := light.TrustOptions{
-			Period: trustedPeriod,
+			Period: trustingPeriod,
 			Height: info.TrustedBlockHeight,
 			Hash:   info.TrustedBlockHash,
 		}
@@ -134,33 +153,100 @@ func NewQueryClientWithDB(ctx context.Context, config *config.Config, info *Trus
 			pv,
 			pvs,
 			store,
-			light.SkippingVerification(light.DefaultTrustLevel),
-			logger,
+			options...,
 		)
 	}
 
 	if err != nil {
+		monitor.Check(err)
 		return nil, err
 	}
 
-	// call refresh every minute
-	go func() {
-		for {
-			time.Sleep(1 * time.Minute)
-			if err := refresh(ctx, lc, trustedPeriod, &lcMutex); err != nil {
-				log.Errorf("light client refresh error: %v", err)
-			}
+	queryClient := &QueryClient{
+		rpcClient:     rpcClient,
+		lightClient:   lc,
+		db:            db,
+		mutex:         &lcMutex,
+		cdc:           codec.NewProtoCodec(makeInterfaceRegistry()),
+		aminoCdc:      codec.NewAminoCodec(codec.NewLegacyAmino()),
+		chainID:       chainID,
+		attackMonitor: monitor,
+		health:        health.NewRegistry(),
+		closeOnce:     &sync.Once{},
+	}
+
+	// the trusting period must be shorter than the unbonding time,
+	// so that validators who signed a block can be still slashed while the block is trusted.
+	unbondingTime, err := queryClient.GetStakingUnbondingTime()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the unbonding time: %w", err)
+	}
+	if trustingPeriod >= unbondingTime {
+		return nil, fmt.Errorf("light client trusting period(%v) must be shorter than the unbonding time(%v)", trustingPeriod, unbondingTime)
+	}
+
+	// the refresh is stopped when the ctx is canceled or the QueryClient is closed
+	refreshCtx, cancel := context.WithCancel(ctx)
+	queryClient.cancel = cancel
+	queryClient.refreshDone = make(chan struct{})
+	go queryClient.runRefresh(refreshCtx, config.Panacea.LightClientRefreshInterval, trustingPeriod)
+
+	return queryClient, nil
+}
+
+// runRefresh calls refresh periodically until the ctx is canceled, and reports its result to the health registry.
+func (q QueryClient) runRefresh(ctx context.Context, interval, trustingPeriod time.Duration) {
+	defer close(q.refreshDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
-	}()
-
-	return &QueryClient{
-		rpcClient:   rpcClient,
-		lightClient: lc,
-		db:          db,
-		mutex:       &lcMutex,
-		cdc:         codec.NewProtoCodec(makeInterfaceRegistry()),
-		aminoCdc:    codec.NewAminoCodec(codec.NewLegacyAmino()),
-		chainID:     chainID,
+
+		if err := q.attackMonitor.Err(); err != nil {
+			q.health.Report(HealthComponentLightClientRefresh, err)
+			continue
+		}
+
+		err := refresh(ctx, q.lightClient, trustingPeriod, q.mutex)
+		if ctx.Err() != nil {
+			// the refresh was interrupted by Close
+			return
+		}
+		if err != nil {
+			q.attackMonitor.Check(err)
+			log.Errorf("light client refresh error: %v", err)
+		}
+		q.health.Report(HealthComponentLightClientRefresh, err)
+	}
+}
+
+// newLightClientOptions returns light client options from the config.
+func newLightClientOptions(conf *config.Config) ([]light.Option, error) {
+	var verificationOption light.Option
+
+	switch conf.Panacea.LightClientVerificationMode {
+	case config.LightClientVerificationModeSequential:
+		verificationOption = light.SequentialVerification()
+	case config.LightClientVerificationModeSkipping:
+		trustLevel, err := tmmath.ParseFraction(conf.Panacea.LightClientTrustLevel)
+		if err != nil {
+			return nil, fmt.Errorf("invalid light client trust level: %w", err)
+		}
+		verificationOption = light.SkippingVerification(trustLevel)
+	default:
+		return nil, fmt.Errorf("invalid light client verification mode: %s", conf.Panacea.LightClientVerificationMode)
+	}
+
+	return []light.Option{
+		verificationOption,
+		light.MaxClockDrift(conf.Panacea.LightClientMaxClockDrift),
+		light.Logger(newTMLogger(conf)),
 	}, nil
 }
 
@@ -179,18 +265,49 @@ func newTMLogger(conf *config.Config) tmlog.Logger {
 	return logger
 }
 
+// safeUpdateLightClient updates the light client, but fails if a light client attack has been detected.
 func (q QueryClient) safeUpdateLightClient(ctx context.Context) (*tmtypes.LightBlock, error) {
+	if err := q.attackMonitor.Err(); err != nil {
+		return nil, err
+	}
+
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
-	return q.lightClient.Update(ctx, time.Now())
+	lightBlock, err := q.lightClient.Update(ctx, time.Now())
+	q.attackMonitor.Check(err)
+
+	return lightBlock, err
 }
 
+// safeVerifyLightBlockAtHeight verifies the light block at the height, but fails if a light client attack has been detected.
 func (q QueryClient) safeVerifyLightBlockAtHeight(ctx context.Context, height int64) (*tmtypes.LightBlock, error) {
+	if err := q.attackMonitor.Err(); err != nil {
+		return nil, err
+	}
+
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
-	return q.lightClient.VerifyLightBlockAtHeight(ctx, height, time.Now())
+	lightBlock, err := q.lightClient.VerifyLightBlockAtHeight(ctx, height, time.Now())
+	q.attackMonitor.Check(err)
+
+	return lightBlock, err
+}
+
+// LightClientAttackStatus returns whether the light client has detected an attack.
+func (q QueryClient) LightClientAttackStatus() LightClientAttackStatus {
+	return q.attackMonitor.Status()
+}
+
+// Health returns the health registry of the background jobs of the QueryClient.
+func (q QueryClient) Health() *health.Registry {
+	return q.health
+}
+
+// LastTrustedHeight returns the last trusted height of the light client.
+func (q QueryClient) LastTrustedHeight() (int64, error) {
+	return q.lightClient.LastTrustedHeight()
 }
 
 // refresh update light block, if the last light block has been updated more than trustPeriod * 2/3 ago.
@@ -220,25 +337,125 @@ func refresh(ctx context.Context, lc *light.Client, trustPeriod time.Duration, m
 }
 
 // GetStoreData get data from panacea with storeKey and key, then verify queried data with light client and merkle proof.
-// the returned data type is ResponseQuery.value ([]byte), so recommend to convert to expected type
+// the returned data type is ResponseQuery.value ([]byte), so recommend to convert to expected type.
+// If the key doesn't exist, ErrNotFound is returned only when the non-membership proof is verified.
 func (q QueryClient) GetStoreData(ctx context.Context, storeKey string, key []byte) ([]byte, error) {
-	var queryHeight int64
+	queryHeight, err := q.getLatestTrustedHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	// get recent light block
-	// if the latest block has already been updated, get LastTrustedHeight
-	trustedBlock, err := q.safeUpdateLightClient(ctx)
+	return q.getStoreDataWithHeight(ctx, queryHeight, storeKey, key)
+}
+
+// GetStoreDataByPrefix gets all key-value pairs whose keys start with the prefix from panacea,
+// then verifies each value with light client and merkle proof at the same trusted height.
+// Keys are listed by a subspace query which doesn't support merkle proofs,
+// so the completeness of the returned list is not guaranteed, but every returned value is verified.
+// If no key exists with the prefix, it returns an empty slice without error.
+// It costs an ABCI query and a light block verification per key, so it returns an error
+// if there are more than maxPrefixQueryKeys keys, rather than making the RPC node serve them all.
+func (q QueryClient) GetStoreDataByPrefix(ctx context.Context, storeKey string, prefix []byte) ([]kv.Pair, error) {
+	queryHeight, err := q.getLatestTrustedHeight(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if trustedBlock == nil {
-		queryHeight, err = q.lightClient.LastTrustedHeight()
+
+	option := client.ABCIQueryOptions{
+		Prove:  false,
+		Height: queryHeight,
+	}
+	result, err := q.abciQueryWithOptions(ctx, fmt.Sprintf("/store/%s/subspace", storeKey), prefix, option)
+	if errors.Is(err, ErrEmptyValue) {
+		return []kv.Pair{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var pairs kv.Pairs
+	if err := pairs.Unmarshal(result.Response.Value); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal kv pairs: %w", err)
+	}
+	if len(pairs.Pairs) > maxPrefixQueryKeys {
+		return nil, fmt.Errorf("too many keys with the prefix(%X): %d, limit %d", prefix, len(pairs.Pairs), maxPrefixQueryKeys)
+	}
+
+	// the subspace query is always served from the latest state regardless of the height,
+	// so the values are re-queried with merkle proofs at the trusted height.
+	verifiedPairs := make([]kv.Pair, 0, len(pairs.Pairs))
+	for _, pair := range pairs.Pairs {
+		if !bytes.HasPrefix(pair.Key, prefix) {
+			return nil, fmt.Errorf("the key(%X) doesn't have the prefix(%X)", pair.Key, prefix)
+		}
+
+		value, err := q.getStoreDataWithHeight(ctx, queryHeight, storeKey, pair.Key)
+		if errors.Is(err, ErrNotFound) {
+			// the key was created after the trusted height
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to verify the value of the key(%X): %w", pair.Key, err)
+		}
+
+		verifiedPairs = append(verifiedPairs, kv.Pair{Key: pair.Key, Value: value})
+	}
+
+	return verifiedPairs, nil
+}
+
+// concatKey returns a new key which is the concatenation of the parts.
+// The prefixes defined by modules, like oracletypes.OracleRegistrationKey, must not be appended in place,
+// because append may write into their backing arrays.
+func concatKey(parts ...[]byte) []byte {
+	var key []byte
+	for _, part := range parts {
+		key = append(key, part...)
+	}
+	return key
+}
+
+// GetStoreDataAtHeight get data from panacea at the height, then verify queried data with the light block at height+1 and merkle proof.
+// If the state or the light block at the height has been pruned, ErrHeightPruned is returned.
+// The height must not be greater than the latest trusted height of the light client.
+func (q QueryClient) GetStoreDataAtHeight(ctx context.Context, height int64, storeKey string, key []byte) ([]byte, error) {
+	// the store query with proof is not allowed at the height <= 1
+	if height <= 1 {
+		return nil, fmt.Errorf("invalid height(%d). height must be greater than 1", height)
+	}
+
+	lastTrustedHeight, err := q.lightClient.LastTrustedHeight()
+	if err != nil {
+		return nil, err
+	}
+	if height > lastTrustedHeight {
+		// the height may be committed after the last update of the light client
+		lastTrustedHeight, err = q.getLatestTrustedHeight(ctx)
 		if err != nil {
 			return nil, err
 		}
-	} else {
-		queryHeight = trustedBlock.Height
+		if height > lastTrustedHeight {
+			return nil, fmt.Errorf("invalid height(%d). height must not be greater than the latest trusted height(%d)", height, lastTrustedHeight)
+		}
+	}
+
+	return q.getStoreDataWithHeight(ctx, height, storeKey, key)
+}
+
+// getLatestTrustedHeight updates the light client and returns the latest trusted height.
+// If the latest block has already been updated, it returns LastTrustedHeight.
+func (q QueryClient) getLatestTrustedHeight(ctx context.Context) (int64, error) {
+	trustedBlock, err := q.safeUpdateLightClient(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if trustedBlock == nil {
+		return q.lightClient.LastTrustedHeight()
 	}
 
+	return trustedBlock.Height, nil
+}
+
+// getStoreDataWithHeight get data from panacea at queryHeight, then verify queried data with light client and merkle proof.
+func (q QueryClient) getStoreDataWithHeight(ctx context.Context, queryHeight int64, storeKey string, key []byte) ([]byte, error) {
 	//set queryOption prove to true
 	option := client.ABCIQueryOptions{
 		Prove:  true,
@@ -259,6 +476,8 @@ func (q QueryClient) GetStoreData(ctx context.Context, storeKey string, key []by
 		if errors.Is(err, provider.ErrHeightTooHigh) {
 			time.Sleep(1 * time.Second)
 			i++
+		} else if errors.Is(err, provider.ErrLightBlockNotFound) {
+			return nil, fmt.Errorf("%w: light block at height(%d) is not found: %v", ErrHeightPruned, queryHeight+1, err)
 		} else if err != nil {
 			return nil, err
 		} else {
@@ -279,6 +498,16 @@ func (q QueryClient) GetStoreData(ctx context.Context, storeKey string, key []by
 	merkleRootKey := types.NewMerkleRoot(nextTrustedBlock.AppHash.Bytes())
 
 	merklePath := types.NewMerklePath(storeKey, string(key))
+
+	// if the value is empty, the key must be proven to be absent from the store.
+	// otherwise, an RPC node could hide existing data by returning an empty value.
+	if len(result.Response.Value) == 0 {
+		if err := merkleProof.VerifyNonMembership(sdkSpecs, merkleRootKey, merklePath); err != nil {
+			return nil, fmt.Errorf("failed to verify the absence of the key: %w", err)
+		}
+		return nil, ErrNotFound
+	}
+
 	err = merkleProof.VerifyMembership(sdkSpecs, merkleRootKey, merklePath, result.Response.Value)
 	if err != nil {
 		return nil, err
@@ -287,12 +516,21 @@ func (q QueryClient) GetStoreData(ctx context.Context, storeKey string, key []by
 	return result.Response.Value, nil
 }
 
+// Close stops the background refresh and closes the DB.
+// It waits for the running refresh to finish, so that the refresh never accesses the closed DB.
 func (q QueryClient) Close() error {
-	return q.db.Close()
+	var err error
+	q.closeOnce.Do(func() {
+		q.cancel()
+		<-q.refreshDone
+		err = q.db.Close()
+	})
+	return err
 }
 
 // abciQueryWithOptions is a wrapper of rpcClient.ABCIQueryWithOptions,
 // but validates the details of result.Response even if rpcClient.ABCIQueryWithOptions returns no error.
+// If opts.Prove is true, an empty value is not treated as an error, so that the caller can verify its absence proof.
 func (q QueryClient) abciQueryWithOptions(ctx context.Context, path string, data tmbytes.HexBytes, opts client.ABCIQueryOptions) (*ctypes.ResultABCIQuery, error) {
 	res, err := q.rpcClient.ABCIQueryWithOptions(ctx, path, data, opts)
 	if err != nil {
@@ -302,12 +540,15 @@ func (q QueryClient) abciQueryWithOptions(ctx context.Context, path string, data
 
 	// Validate the response.
 	if resp.IsErr() {
-		return nil, fmt.Errorf("err response code: %v", resp.Code)
+		if isHeightPrunedResponse(resp) {
+			return nil, fmt.Errorf("%w: height(%d): %s", ErrHeightPruned, opts.Height, resp.Log)
+		}
+		return nil, fmt.Errorf("err response code: %v, log: %s", resp.Code, resp.Log)
 	}
 	if len(resp.Key) == 0 {
 		return nil, ErrEmptyKey
 	}
-	if len(resp.Value) == 0 {
+	if !opts.Prove && len(resp.Value) == 0 {
 		return nil, ErrEmptyValue
 	}
 	if opts.Prove && (resp.ProofOps == nil || len(resp.ProofOps.Ops) == 0) {
@@ -345,6 +586,28 @@ func (q QueryClient) GetAccount(address string) (authtypes.AccountI, error) {
 	return account, nil
 }
 
+// GetAccountAt returns account from address at the height.
+func (q QueryClient) GetAccountAt(height int64, address string) (authtypes.AccountI, error) {
+	acc, err := GetAccAddressFromBech32(address)
+	if err != nil {
+		return nil, err
+	}
+
+	key := authtypes.AddressStoreKey(acc)
+	bz, err := q.GetStoreDataAtHeight(context.Background(), height, authtypes.StoreKey, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var account authtypes.AccountI
+	err = q.cdc.UnmarshalInterface(bz, &account)
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
 func (q QueryClient) GetOracleRegistration(oracleAddr, uniqueID string) (*oracletypes.OracleRegistration, error) {
 
 	acc, err := GetAccAddressFromBech32(oracleAddr)
@@ -372,64 +635,75 @@ func (q QueryClient) GetLightBlock(height int64) (*tmtypes.LightBlock, error) {
 	return q.safeVerifyLightBlockAtHeight(context.Background(), height)
 }
 
-func (q QueryClient) GetOracleParamsPublicKey() (*btcec.PublicKey, error) {
-	pubKeyBase64Bz, err := q.GetStoreData(context.Background(), paramstypes.StoreKey, append(append([]byte(oracletypes.StoreKey), '/'), oracletypes.KeyOraclePublicKey...))
+func (q QueryClient) GetOracleUpgradeInfo() (*oracletypes.OracleUpgradeInfo, error) {
+	oracleUpgradeInfoBz, err := q.GetStoreData(context.Background(), oracletypes.StoreKey, oracletypes.OracleUpgradeInfoKey)
 	if err != nil {
 		return nil, err
 	}
-	// TODO: don't need to handle this case after merging https://github.com/medibloc/panacea-doracle/pull/68
-	if pubKeyBase64Bz == nil {
-		return nil, errors.New("the oracle public key's value is nil")
+
+	var oracleUpgradeInfo oracletypes.OracleUpgradeInfo
+	if err := q.cdc.UnmarshalLengthPrefixed(oracleUpgradeInfoBz, &oracleUpgradeInfo); err != nil {
+		return nil, err
 	}
+	return &oracleUpgradeInfo, nil
+}
+func (q QueryClient) GetDeal(dealID uint64) (*datadealtypes.Deal, error) {
+	key := datadealtypes.GetDealKey(dealID)
 
-	// If you get a value from params, you should not use protoCodec, but use legacyAmino.
-	var pubKeyBase64 string
-	err = q.aminoCdc.LegacyAmino.UnmarshalJSON(pubKeyBase64Bz, &pubKeyBase64)
+	bz, err := q.GetStoreData(context.Background(), datadealtypes.StoreKey, key)
 	if err != nil {
 		return nil, err
 	}
 
-	pubKeyBz, err := base64.StdEncoding.DecodeString(pubKeyBase64)
+	var deal datadealtypes.Deal
+	err = q.cdc.UnmarshalLengthPrefixed(bz, &deal)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode base64 pubkey: %w", err)
+		return nil, err
 	}
 
-	return btcec.ParsePubKey(pubKeyBz, btcec.S256())
+	return &deal, nil
 }
 
-func (q QueryClient) GetOracleUpgradeInfo() (*oracletypes.OracleUpgradeInfo, error) {
-	oracleUpgradeInfoBz, err := q.GetStoreData(context.Background(), oracletypes.StoreKey, oracletypes.OracleUpgradeInfoKey)
+// GetDealAt returns the deal at the height.
+func (q QueryClient) GetDealAt(height int64, dealID uint64) (*datadealtypes.Deal, error) {
+	key := datadealtypes.GetDealKey(dealID)
+
+	bz, err := q.GetStoreDataAtHeight(context.Background(), height, datadealtypes.StoreKey, key)
 	if err != nil {
 		return nil, err
 	}
 
-	var oracleUpgradeInfo oracletypes.OracleUpgradeInfo
-	if err := q.cdc.UnmarshalLengthPrefixed(oracleUpgradeInfoBz, &oracleUpgradeInfo); err != nil {
+	var deal datadealtypes.Deal
+	err = q.cdc.UnmarshalLengthPrefixed(bz, &deal)
+	if err != nil {
 		return nil, err
 	}
-	return &oracleUpgradeInfo, nil
+
+	return &deal, nil
 }
-func (q QueryClient) GetDeal(dealID uint64) (*datadealtypes.Deal, error) {
-	key := datadealtypes.GetDealKey(dealID)
+
+func (q QueryClient) GetDataSale(dataHash string, dealID uint64) (*datadealtypes.DataSale, error) {
+	key := datadealtypes.GetDataSaleKey(dataHash, dealID)
 
 	bz, err := q.GetStoreData(context.Background(), datadealtypes.StoreKey, key)
 	if err != nil {
 		return nil, err
 	}
 
-	var deal datadealtypes.Deal
-	err = q.cdc.UnmarshalLengthPrefixed(bz, &deal)
+	var dataSale datadealtypes.DataSale
+	err = q.cdc.UnmarshalLengthPrefixed(bz, &dataSale)
 	if err != nil {
 		return nil, err
 	}
 
-	return &deal, nil
+	return &dataSale, nil
 }
 
-func (q QueryClient) GetDataSale(dataHash string, dealID uint64) (*datadealtypes.DataSale, error) {
+// GetDataSaleAt returns the data sale at the height.
+func (q QueryClient) GetDataSaleAt(height int64, dataHash string, dealID uint64) (*datadealtypes.DataSale, error) {
 	key := datadealtypes.GetDataSaleKey(dataHash, dealID)
 
-	bz, err := q.GetStoreData(context.Background(), datadealtypes.StoreKey, key)
+	bz, err := q.GetStoreDataAtHeight(context.Background(), height, datadealtypes.StoreKey, key)
 	if err != nil {
 		return nil, err
 	}
@@ -442,3 +716,69 @@ func (q QueryClient) GetDataSale(dataHash string, dealID uint64) (*datadealtypes
 
 	return &dataSale, nil
 }
+
+// GetOracleRegistrations returns all oracle registrations with the uniqueID.
+// Each registration is verified by its own query, so the cost is proportional to the number of registrations.
+func (q QueryClient) GetOracleRegistrations(uniqueID string) ([]*oracletypes.OracleRegistration, error) {
+	prefix := concatKey(oracletypes.OracleRegistrationKey, []byte(uniqueID), oracletypes.IndexSeparator)
+
+	pairs, err := q.GetStoreDataByPrefix(context.Background(), oracletypes.StoreKey, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	oracleRegistrations := make([]*oracletypes.OracleRegistration, 0, len(pairs))
+	for _, pair := range pairs {
+		var oracleRegistration oracletypes.OracleRegistration
+		if err := q.cdc.UnmarshalLengthPrefixed(pair.Value, &oracleRegistration); err != nil {
+			return nil, err
+		}
+		oracleRegistrations = append(oracleRegistrations, &oracleRegistration)
+	}
+
+	return oracleRegistrations, nil
+}
+
+// GetDataSales returns all data sales of the deal.
+// Each data sale is verified by its own query, so the cost is proportional to the number of data sales.
+func (q QueryClient) GetDataSales(dealID uint64) ([]*datadealtypes.DataSale, error) {
+	prefix := concatKey(datadealtypes.DataSaleKey, sdktypes.Uint64ToBigEndian(dealID), datadealtypes.KeyIndexSeparator)
+
+	pairs, err := q.GetStoreDataByPrefix(context.Background(), datadealtypes.StoreKey, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	dataSales := make([]*datadealtypes.DataSale, 0, len(pairs))
+	for _, pair := range pairs {
+		var dataSale datadealtypes.DataSale
+		if err := q.cdc.UnmarshalLengthPrefixed(pair.Value, &dataSale); err != nil {
+			return nil, err
+		}
+		dataSales = append(dataSales, &dataSale)
+	}
+
+	return dataSales, nil
+}
+
+// GetActiveDeals returns all deals whose status is DEAL_STATUS_ACTIVE.
+// All deals including inactive ones are verified one by one, so the cost is proportional to the number of deals.
+func (q QueryClient) GetActiveDeals() ([]*datadealtypes.Deal, error) {
+	pairs, err := q.GetStoreDataByPrefix(context.Background(), datadealtypes.StoreKey, datadealtypes.KeyPrefixDeals)
+	if err != nil {
+		return nil, err
+	}
+
+	deals := make([]*datadealtypes.Deal, 0)
+	for _, pair := range pairs {
+		var deal datadealtypes.Deal
+		if err := q.cdc.UnmarshalLengthPrefixed(pair.Value, &deal); err != nil {
+			return nil, err
+		}
+		if deal.Status == datadealtypes.DEAL_STATUS_ACTIVE {
+			deals = append(deals, &deal)
+		}
+	}
+
+	return deals, nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/cosmos/cosmos-sdk/types/bech32"
 	"github.com/cosmos/go-bip39"
+	oracletypes "github.com/medibloc/panacea-core/v2/x/oracle/types"
 	"github.com/medibloc/panacea-doracle/integration/rest"
 	"github.com/medibloc/panacea-doracle/integration/suite"
 	dbm "github.com/tendermint/tm-db"
@@ -75,6 +76,26 @@ func (suite *queryClientTestSuite) TestGetAccount() {
 	wg.Wait()
 }
 
+func (suite *queryClientTestSuite) TestGetAccountAt() {
+	trustedBlockInfo, conf := suite.prepare()
+
+	queryClient, err := NewQueryClientWithDB(context.Background(), conf, trustedBlockInfo, dbm.NewMemDB())
+	require.NoError(suite.T(), err)
+	defer queryClient.Close()
+
+	accAddr := suite.AccAddressFromMnemonic(suite.validatorMnemonic, 0, 0)
+
+	acc, err := queryClient.GetAccountAt(trustedBlockInfo.TrustedBlockHeight, accAddr)
+	require.NoError(suite.T(), err)
+
+	address, err := bech32.ConvertAndEncode("panacea", acc.GetPubKey().Address().Bytes())
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), accAddr, address)
+
+	_, err = queryClient.GetAccountAt(1, accAddr)
+	require.Error(suite.T(), err)
+}
+
 func (suite *queryClientTestSuite) TestLoadQueryClient() {
 	trustedBlockInfo, conf := suite.prepare()
 
@@ -99,7 +120,7 @@ func (suite *queryClientTestSuite) TestLoadQueryClient() {
 	require.GreaterOrEqual(suite.T(), lastTrustedHeight2, lastTrustedHeight)
 }
 
-func (suite *queryClientTestSuite) TestGetOracleUpgradeInfoEmptyValue() {
+func (suite *queryClientTestSuite) TestGetOracleUpgradeInfoNotFound() {
 	trustedBlockInfo, conf := suite.prepare()
 
 	queryClient, err := NewQueryClientWithDB(context.Background(), conf, trustedBlockInfo, dbm.NewMemDB())
@@ -108,7 +129,34 @@ func (suite *queryClientTestSuite) TestGetOracleUpgradeInfoEmptyValue() {
 
 	upgradeInfo, err := queryClient.GetOracleUpgradeInfo()
 	require.Nil(suite.T(), upgradeInfo)
-	require.ErrorIs(suite.T(), err, ErrEmptyValue)
+	require.ErrorIs(suite.T(), err, ErrNotFound)
+}
+
+func (suite *queryClientTestSuite) TestGetOracleRegistrationsEmpty() {
+	trustedBlockInfo, conf := suite.prepare()
+
+	queryClient, err := NewQueryClientWithDB(context.Background(), conf, trustedBlockInfo, dbm.NewMemDB())
+	require.NoError(suite.T(), err)
+	defer queryClient.Close()
+
+	oracleRegistrations, err := queryClient.GetOracleRegistrations("uniqueID")
+	require.NoError(suite.T(), err)
+	require.Empty(suite.T(), oracleRegistrations)
+}
+
+func (suite *queryClientTestSuite) TestGetOracleParams() {
+	trustedBlockInfo, conf := suite.prepare()
+
+	queryClient, err := NewQueryClientWithDB(context.Background(), conf, trustedBlockInfo, dbm.NewMemDB())
+	require.NoError(suite.T(), err)
+	defer queryClient.Close()
+
+	// the test chain is initialized with the default oracle params
+	params, err := queryClient.GetOracleParams()
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), oracletypes.DefaultParams().VoteParams.VotingPeriod, params.VoteParams.VotingPeriod)
+	require.True(suite.T(), oracletypes.DefaultParams().VoteParams.Threshold.Equal(params.VoteParams.Threshold))
+	require.True(suite.T(), oracletypes.DefaultParams().OracleCommissionRate.Equal(params.OracleCommissionRate))
 }
 
 func (suite *queryClientTestSuite) prepare() (*TrustedBlockInfo, *config.Config) {
@@ -120,15 +168,24 @@ func (suite *queryClientTestSuite) prepare() (*TrustedBlockInfo, *config.Config)
 		TrustedBlockHash:   hash,
 	}
 
-	conf := &config.Config{
-		Panacea: config.PanaceaConfig{
-			GRPCAddr:                suite.PanaceaEndpoint("tcp", 9090),
-			RPCAddr:                 suite.PanaceaEndpoint("tcp", 26657),
-			ChainID:                 suite.chainID,
-			LightClientPrimaryAddr:  suite.PanaceaEndpoint("tcp", 26657),
-			LightClientWitnessAddrs: []string{suite.PanaceaEndpoint("tcp", 26657)},
-		},
-	}
+	conf := config.DefaultConfig()
+	conf.Panacea.GRPCAddr = suite.PanaceaEndpoint("tcp", 9090)
+	conf.Panacea.RPCAddr = suite.PanaceaEndpoint("tcp", 26657)
+	conf.Panacea.ChainID = suite.chainID
+	conf.Panacea.LightClientPrimaryAddr = suite.PanaceaEndpoint("tcp", 26657)
+	conf.Panacea.LightClientWitnessAddrs = []string{suite.PanaceaEndpoint("tcp", 26657)}
 
 	return trustedBlockInfo, conf
 }
+
+func TestConcatKey(t *testing.T) {
+	// a prefix with spare capacity, which append would write into
+	prefix := make([]byte, 1, 8)
+	prefix[0] = 0x01
+
+	key1 := concatKey(prefix, []byte("a"), []byte{0xff})
+	key2 := concatKey(prefix, []byte("b"), []byte{0xff})
+	require.Equal(t, []byte{0x01, 'a', 0xff}, key1)
+	require.Equal(t, []byte{0x01, 'b', 0xff}, key2)
+	require.Equal(t, []byte{0x01}, prefix)
+}
@@ -0,0 +1,78 @@
+package panacea
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/medibloc/panacea-doracle/config"
+	"github.com/tendermint/tendermint/light/provider"
+	tmhttp "github.com/tendermint/tendermint/light/provider/http"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+const (
+	// maxWitnessLagRetries is the number of retries to wait for a witness which hasn't reached the height yet
+	maxWitnessLagRetries = 10
+)
+
+// DiscoverTrustedBlockInfo fetches the latest block from the primary,
+// and requires every witness to agree on the hash of the block at the same height.
+// It returns the agreed block, so that it can be used as a trusted block.
+func DiscoverTrustedBlockInfo(ctx context.Context, conf *config.Config) (*TrustedBlockInfo, *tmtypes.LightBlock, error) {
+	chainID := conf.Panacea.ChainID
+
+	primary, err := tmhttp.New(chainID, conf.Panacea.LightClientPrimaryAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lightBlock, err := primary.LightBlock(ctx, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the latest block from the primary(%s): %w", conf.Panacea.LightClientPrimaryAddr, err)
+	}
+
+	if len(conf.Panacea.LightClientWitnessAddrs) == 0 {
+		return nil, nil, errors.New("no witness to check the block")
+	}
+
+	for _, witnessAddr := range conf.Panacea.LightClientWitnessAddrs {
+		witness, err := tmhttp.New(chainID, witnessAddr)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		witnessBlock, err := getLightBlockWithRetry(ctx, witness, lightBlock.Height)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get the block at height(%d) from the witness(%s): %w", lightBlock.Height, witnessAddr, err)
+		}
+
+		if !bytes.Equal(witnessBlock.Hash(), lightBlock.Hash()) {
+			return nil, nil, fmt.Errorf("the witness(%s) doesn't agree on the block at height(%d). primary(%X), witness(%X)",
+				witnessAddr,
+				lightBlock.Height,
+				lightBlock.Hash(),
+				witnessBlock.Hash(),
+			)
+		}
+	}
+
+	return &TrustedBlockInfo{
+		TrustedBlockHeight: lightBlock.Height,
+		TrustedBlockHash:   lightBlock.Hash(),
+	}, lightBlock, nil
+}
+
+// getLightBlockWithRetry gets the light block at the height, waiting for the provider which hasn't reached the height yet.
+func getLightBlockWithRetry(ctx context.Context, pv provider.Provider, height int64) (*tmtypes.LightBlock, error) {
+	for i := 0; ; i++ {
+		lightBlock, err := pv.LightBlock(ctx, height)
+		if errors.Is(err, provider.ErrHeightTooHigh) && i < maxWitnessLagRetries {
+			time.Sleep(1 * time.Second)
+			continue
+		}
+		return lightBlock, err
+	}
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/medibloc/panacea-doracle/sgx"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// attestationDomain separates the report data of the attestation endpoint from the report data of other usages.
+	attestationDomain = "panacea-doracle/attestation/v0"
+	maxNonceLength    = 64
+	// maxConcurrentAttestations is the maximum number of remote reports generated at the same time.
+	// Generating a remote report takes the quoting enclave for a while, so the endpoint must not be flooded.
+	maxConcurrentAttestations = 4
+)
+
+type attestationResponse struct {
+	Nonce              string             `json:"nonce"`
+	OraclePubKeyBase64 string             `json:"oracle_public_key_base64"`
+	NodePubKeyBase64   string             `json:"node_public_key_base64,omitempty"`
+	RemoteReportBase64 string             `json:"remote_report_base64"`
+	Enclave            attestationEnclave `json:"enclave"`
+}
+
+type attestationEnclave struct {
+	UniqueID  string `json:"unique_id"`
+	SignerID  string `json:"signer_id"`
+	ProductID string `json:"product_id"`
+}
+
+// AttestationReportData returns the report data of the remote report served by the attestation endpoint.
+// It binds the oracle public key, the node public key and the nonce chosen by the client,
+// so that the client can verify that the report was freshly generated by the oracle holding the keys.
+// The node public key can be empty if the oracle has no node key (e.g. the genesis oracle).
+func AttestationReportData(oraclePubKey, nodePubKey, nonce []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(attestationDomain))
+	for _, field := range [][]byte{oraclePubKey, nodePubKey, nonce} {
+		// each field is prefixed by its length, so that fields cannot be shifted to each other
+		var length [4]byte
+		binary.BigEndian.PutUint32(length[:], uint32(len(field)))
+		h.Write(length[:])
+		h.Write(field)
+	}
+	return h.Sum(nil)
+}
+
+// newAttestationHandler returns a handler which responds a fresh remote report with the nonce given by the client.
+// Sellers and buyers can verify the report to check that they are talking to a genuine oracle.
+// If maxConcurrentAttestations requests are already being handled, it responds 429 Too Many Requests.
+func newAttestationHandler(oraclePubKey *btcec.PublicKey, nodePubKey *btcec.PublicKey, enclaveInfo *sgx.EnclaveInfo) http.HandlerFunc {
+	return limitConcurrency(maxConcurrentAttestations, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		nonce := r.URL.Query().Get("nonce")
+		if nonce == "" {
+			http.Error(w, "nonce is required", http.StatusBadRequest)
+			return
+		}
+		if len(nonce) > maxNonceLength {
+			http.Error(w, "nonce is too long", http.StatusBadRequest)
+			return
+		}
+
+		oraclePubKeyBz := oraclePubKey.SerializeCompressed()
+		var nodePubKeyBz []byte
+		if nodePubKey != nil {
+			nodePubKeyBz = nodePubKey.SerializeCompressed()
+		}
+
+		report, err := sgx.GenerateRemoteReport(AttestationReportData(oraclePubKeyBz, nodePubKeyBz, []byte(nonce)))
+		if err != nil {
+			log.Errorf("failed to generate remote report: %v", err)
+			http.Error(w, "failed to generate remote report", http.StatusInternalServerError)
+			return
+		}
+
+		resp := attestationResponse{
+			Nonce:              nonce,
+			OraclePubKeyBase64: base64.StdEncoding.EncodeToString(oraclePubKeyBz),
+			RemoteReportBase64: base64.StdEncoding.EncodeToString(report),
+			Enclave: attestationEnclave{
+				UniqueID:  hex.EncodeToString(enclaveInfo.UniqueID),
+				SignerID:  hex.EncodeToString(enclaveInfo.SignerID),
+				ProductID: hex.EncodeToString(enclaveInfo.ProductID),
+			},
+		}
+		if nodePubKeyBz != nil {
+			resp.NodePubKeyBase64 = base64.StdEncoding.EncodeToString(nodePubKeyBz)
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+// limitConcurrency returns a handler which handles up to 'limit' requests at the same time.
+// Other requests are not queued, but rejected with 429 Too Many Requests, so that clients can retry later.
+func limitConcurrency(limit int, handler http.HandlerFunc) http.HandlerFunc {
+	sem := make(chan struct{}, limit)
+	return func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+		default:
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+		handler(w, r)
+	}
+}
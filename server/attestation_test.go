@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/medibloc/panacea-doracle/crypto"
+	"github.com/medibloc/panacea-doracle/sgx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttestationHandler(t *testing.T) {
+	info := sgx.NewEnclaveInfo([]byte{1}, []byte("signer-id"), []byte("unique-id"))
+	e, err := sgx.NewSimulatedEnclave(filepath.Join(t.TempDir(), "seal_key"), *info)
+	require.NoError(t, err)
+	prev := sgx.CurrentEnclave()
+	sgx.SetEnclave(e)
+	t.Cleanup(func() { sgx.SetEnclave(prev) })
+
+	oraclePrivKey, err := crypto.NewPrivKey()
+	require.NoError(t, err)
+	nodePrivKey, err := crypto.NewPrivKey()
+	require.NoError(t, err)
+
+	handler := newAttestationHandler(oraclePrivKey.PubKey(), nodePrivKey.PubKey(), info)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/v0/attestation?nonce=my-nonce", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp attestationResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Equal(t, "my-nonce", resp.Nonce)
+	require.Equal(t, hex.EncodeToString(info.UniqueID), resp.Enclave.UniqueID)
+
+	oraclePubKey, err := base64.StdEncoding.DecodeString(resp.OraclePubKeyBase64)
+	require.NoError(t, err)
+	require.Equal(t, oraclePrivKey.PubKey().SerializeCompressed(), oraclePubKey)
+	nodePubKey, err := base64.StdEncoding.DecodeString(resp.NodePubKeyBase64)
+	require.NoError(t, err)
+	require.Equal(t, nodePrivKey.PubKey().SerializeCompressed(), nodePubKey)
+
+	report, err := base64.StdEncoding.DecodeString(resp.RemoteReportBase64)
+	require.NoError(t, err)
+	require.NoError(t, sgx.VerifyRemoteReport(report, AttestationReportData(oraclePubKey, nodePubKey, []byte("my-nonce")), *info))
+
+	// the report doesn't match another nonce
+	require.Error(t, sgx.VerifyRemoteReport(report, AttestationReportData(oraclePubKey, nodePubKey, []byte("other-nonce")), *info))
+}
+
+func TestAttestationHandlerInvalidNonce(t *testing.T) {
+	oraclePrivKey, err := crypto.NewPrivKey()
+	require.NoError(t, err)
+	handler := newAttestationHandler(oraclePrivKey.PubKey(), nil, sgx.NewEnclaveInfo(nil, nil, nil))
+
+	for _, target := range []string{
+		"/v0/attestation",
+		"/v0/attestation?nonce=" + strings.Repeat("a", maxNonceLength+1),
+	} {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		require.Equal(t, http.StatusBadRequest, rec.Code, target)
+	}
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/v0/attestation?nonce=a", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestLimitConcurrency(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := limitConcurrency(2, func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// two requests are being handled
+	codes := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			codes <- rec.Code
+		}()
+		<-started
+	}
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+	close(release)
+	require.Equal(t, http.StatusOK, <-codes)
+	require.Equal(t, http.StatusOK, <-codes)
+
+	// the slots are released
+	go func() { <-started }()
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
@@ -4,18 +4,36 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/medibloc/panacea-doracle/config"
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/medibloc/panacea-doracle/config"
+	"github.com/medibloc/panacea-doracle/panacea"
+	"github.com/medibloc/panacea-doracle/sgx"
 	log "github.com/sirupsen/logrus"
 )
 
-func Run(conf *config.Config) error {
+// Service provides what the HTTP server serves.
+type Service interface {
+	QueryClient() *panacea.QueryClient
+	OraclePrivKey() *btcec.PrivateKey
+	// NodePubKey returns nil if the oracle has no node key.
+	NodePubKey() *btcec.PublicKey
+	EnclaveInfo() *sgx.EnclaveInfo
+}
+
+func Run(conf *config.Config, svc Service) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/status", newStatusHandler(svc.QueryClient()))
+	mux.HandleFunc("/v0/attestation", newAttestationHandler(svc.OraclePrivKey().PubKey(), svc.NodePubKey(), svc.EnclaveInfo()))
+
 	server := &http.Server{
 		Addr:         conf.ListenAddr,
+		Handler:      mux,
 		WriteTimeout: 15 * time.Second,
 		ReadTimeout:  15 * time.Second,
 	}
@@ -33,7 +51,7 @@ func Run(conf *config.Config) error {
 	}()
 
 	signalCh := make(chan os.Signal, 1)
-	signal.Notify(signalCh, os.Interrupt)
+	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
 	select {
 	case err := <-httpServerErrCh:
 		if err != nil {
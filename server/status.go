@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/medibloc/panacea-doracle/health"
+	"github.com/medibloc/panacea-doracle/panacea"
+	log "github.com/sirupsen/logrus"
+)
+
+type statusResponse struct {
+	LightClient lightClientStatus        `json:"light_client"`
+	Health      map[string]health.Status `json:"health"`
+}
+
+type lightClientStatus struct {
+	LastTrustedHeight int64                           `json:"last_trusted_height"`
+	Attack            panacea.LightClientAttackStatus `json:"attack"`
+}
+
+// newStatusHandler returns a handler which shows the status of the oracle.
+// If a light client attack has been detected, it responds with 503 Service Unavailable.
+// Failures of background jobs are shown in the health, but they don't change the status code,
+// because they are usually transient and retried.
+func newStatusHandler(queryClient *panacea.QueryClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		lastTrustedHeight, err := queryClient.LastTrustedHeight()
+		if err != nil {
+			log.Errorf("failed to get last trusted height: %v", err)
+			http.Error(w, "failed to get last trusted height", http.StatusInternalServerError)
+			return
+		}
+
+		resp := statusResponse{
+			LightClient: lightClientStatus{
+				LastTrustedHeight: lastTrustedHeight,
+				Attack:            queryClient.LightClientAttackStatus(),
+			},
+			Health: queryClient.Health().Statuses(),
+		}
+
+		statusCode := http.StatusOK
+		if resp.LightClient.Attack.Detected {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		writeJSON(w, statusCode, resp)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Errorf("failed to write response: %v", err)
+	}
+}
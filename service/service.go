@@ -6,25 +6,32 @@ import (
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/medibloc/panacea-doracle/config"
+	"github.com/medibloc/panacea-doracle/content"
 	"github.com/medibloc/panacea-doracle/crypto"
 	"github.com/medibloc/panacea-doracle/event"
 	"github.com/medibloc/panacea-doracle/ipfs"
 	"github.com/medibloc/panacea-doracle/panacea"
 	"github.com/medibloc/panacea-doracle/sgx"
+	"github.com/medibloc/panacea-doracle/store"
 	log "github.com/sirupsen/logrus"
+	tos "github.com/tendermint/tendermint/libs/os"
 )
 
 type Service struct {
 	conf        *config.Config
 	enclaveInfo *sgx.EnclaveInfo
+	trustPolicy *sgx.TrustPolicy
 
 	oracleAccount *panacea.OracleAccount
 	oraclePrivKey *btcec.PrivateKey
+	nodePubKey    *btcec.PublicKey
 
 	queryClient *panacea.QueryClient
 	grpcClient  *panacea.GrpcClient
 	subscriber  *event.PanaceaSubscriber
 	ipfs        *ipfs.Ipfs
+	storage     store.Storage
+	resolver    *content.Resolver
 }
 
 func New(conf *config.Config) (*Service, error) {
@@ -32,18 +39,40 @@ func New(conf *config.Config) (*Service, error) {
 	if err != nil {
 		return nil, err
 	}
-	oraclePrivKeyBz, err := sgx.UnsealFromFile(conf.AbsOraclePrivKeyPath())
+	oraclePrivKeyBz, err := sgx.UnsealFromFile(conf.AbsOraclePrivKeyPath(), sgx.SealedFileTypeOracleKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unseal oracle_priv_key.sealed file: %w", err)
 	}
 
 	oraclePrivKey, _ := crypto.PrivKeyFromBytes(oraclePrivKeyBz)
 
+	nodePubKey, err := loadNodePubKey(conf)
+	if err != nil {
+		return nil, err
+	}
+
 	selfEnclaveInfo, err := sgx.GetSelfEnclaveInfo()
 	if err != nil {
 		return nil, fmt.Errorf("failed to set self-enclave info: %w", err)
 	}
 
+	trustPolicy, err := sgx.NewTrustPolicy(conf.Enclave, selfEnclaveInfo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trust policy: %w", err)
+	}
+
+	newIpfs := ipfs.NewIpfs(conf)
+
+	storage, err := store.NewStorage(conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage: %w", err)
+	}
+
+	contentResolver, err := content.NewResolver(conf, newIpfs, storage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create content resolver: %w", err)
+	}
+
 	queryClient, err := panacea.LoadQueryClient(context.Background(), conf)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load query client: %w", err)
@@ -68,20 +97,38 @@ func New(conf *config.Config) (*Service, error) {
 		return nil, fmt.Errorf("failed to init subscriber: %w", err)
 	}
 
-	newIpfs := ipfs.NewIpfs(conf.Ipfs.IpfsNodeAddr)
-
 	return &Service{
 		conf:          conf,
 		oracleAccount: oracleAccount,
 		oraclePrivKey: oraclePrivKey,
+		nodePubKey:    nodePubKey,
 		enclaveInfo:   selfEnclaveInfo,
+		trustPolicy:   trustPolicy,
 		queryClient:   queryClient,
 		grpcClient:    grpcClient,
 		subscriber:    subscriber,
 		ipfs:          newIpfs,
+		storage:       storage,
+		resolver:      contentResolver,
 	}, nil
 }
 
+// loadNodePubKey returns the public key of the sealed node key.
+// It returns nil if the node key doesn't exist, because the genesis oracle has no node key.
+func loadNodePubKey(conf *config.Config) (*btcec.PublicKey, error) {
+	nodePrivKeyPath := conf.AbsNodePrivKeyPath()
+	if !tos.FileExists(nodePrivKeyPath) {
+		return nil, nil
+	}
+
+	nodePrivKeyBz, err := sgx.UnsealFromFile(nodePrivKeyPath, sgx.SealedFileTypeNodeKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unseal node_priv_key.sealed file: %w", err)
+	}
+	_, nodePubKey := crypto.PrivKeyFromBytes(nodePrivKeyBz)
+	return nodePubKey, nil
+}
+
 func (s *Service) StartSubscriptions(events ...event.Event) error {
 	return s.subscriber.Run(events...)
 }
@@ -112,10 +159,19 @@ func (s *Service) OraclePrivKey() *btcec.PrivateKey {
 	return s.oraclePrivKey
 }
 
+// NodePubKey returns nil if the oracle has no node key.
+func (s *Service) NodePubKey() *btcec.PublicKey {
+	return s.nodePubKey
+}
+
 func (s *Service) EnclaveInfo() *sgx.EnclaveInfo {
 	return s.enclaveInfo
 }
 
+func (s *Service) TrustPolicy() *sgx.TrustPolicy {
+	return s.trustPolicy
+}
+
 func (s *Service) GRPCClient() *panacea.GrpcClient {
 	return s.grpcClient
 }
@@ -128,6 +184,15 @@ func (s *Service) Ipfs() *ipfs.Ipfs {
 	return s.ipfs
 }
 
+// Storage returns nil if IPFS is used as the storage of data.
+func (s *Service) Storage() store.Storage {
+	return s.storage
+}
+
+func (s *Service) ContentResolver() *content.Resolver {
+	return s.resolver
+}
+
 func (s *Service) BroadcastTx(txBytes []byte) (int64, string, error) {
 	resp, err := s.GRPCClient().BroadcastTx(txBytes)
 	if err != nil {
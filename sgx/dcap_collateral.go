@@ -0,0 +1,309 @@
+package sgx
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// The file names in a collateral directory.
+// They can be downloaded from the Intel PCS (https://api.trustedservices.intel.com/sgx/certification/v3),
+// or from the PCCS of a cloud provider.
+const (
+	// CollateralRootCAFileName is the Intel SGX Root CA certificate in PEM.
+	// It must be downloaded from Intel (https://certificates.trustedservices.intel.com/Intel_SGX_Provisioning_Certification_RootCA.pem),
+	// because all other collateral is trusted only if it is signed by this CA.
+	CollateralRootCAFileName = "root_ca.pem"
+	// CollateralTCBInfoFileName is the response body of /tcb?fmspc=<FMSPC>.
+	CollateralTCBInfoFileName = "tcb_info.json"
+	// CollateralTCBInfoIssuerChainFileName is the 'TCB-Info-Issuer-Chain' header of /tcb, which is URL-decoded.
+	CollateralTCBInfoIssuerChainFileName = "tcb_info_issuer_chain.pem"
+	// CollateralQEIdentityFileName is the response body of /qe/identity.
+	CollateralQEIdentityFileName = "qe_identity.json"
+	// CollateralRootCACRLFileName is the CRL of the root CA in DER or PEM. It is optional.
+	CollateralRootCACRLFileName = "root_ca_crl.der"
+	// CollateralPCKCRLFileName is the response body of /pckcrl?ca=<processor|platform> in DER or PEM. It is optional.
+	CollateralPCKCRLFileName = "pck_crl.der"
+)
+
+// IntelSGXRootCAPublicKeySHA256 is the SHA256 hash of the public key (SubjectPublicKeyInfo) of the Intel SGX Root CA.
+// The root CA in the collateral must have this public key, so that a root CA of other parties can't be given as collateral.
+const IntelSGXRootCAPublicKeySHA256 = "a0af031289f5d5d4132f9186068a7fc13628633ba235777472e29b6b6c67a49e"
+
+// trustedRootCAPublicKeyHashes are the public key hashes of root CAs which are accepted in the collateral.
+// Tests add the root CA of their test PKI.
+var trustedRootCAPublicKeyHashes = []string{IntelSGXRootCAPublicKeySHA256}
+
+// Collateral is the data needed to verify SGX ECDSA quotes without the Intel quote provider library.
+type Collateral struct {
+	RootCA              *x509.Certificate
+	TCBInfoIssuerChain  []*x509.Certificate
+	RawTCBInfo          []byte
+	RawQEIdentity       []byte
+	RootCACRL           *x509.RevocationList
+	PCKCRL              *x509.RevocationList
+	tcbInfo             tcbInfo
+	tcbInfoSignature    []byte
+	qeIdentity          qeIdentity
+	qeIdentitySignature []byte
+}
+
+type tcbInfo struct {
+	ID         string     `json:"id"`
+	Version    int        `json:"version"`
+	IssueDate  time.Time  `json:"issueDate"`
+	NextUpdate time.Time  `json:"nextUpdate"`
+	FMSPC      string     `json:"fmspc"`
+	PCEID      string     `json:"pceId"`
+	TCBLevels  []tcbLevel `json:"tcbLevels"`
+}
+
+type tcbLevel struct {
+	TCB         tcbLevelTCB `json:"tcb"`
+	TCBStatus   string      `json:"tcbStatus"`
+	AdvisoryIDs []string    `json:"advisoryIDs"`
+}
+
+// tcbLevelTCB is the TCB of a level in the TCB info.
+// The SVNs of components are 'sgxtcbcomponents' in version 3, and 'sgxtcbcompNNsvn' in version 2.
+type tcbLevelTCB struct {
+	SGXTCBComponentSVNs [16]int
+	PCESVN              int
+}
+
+func (t *tcbLevelTCB) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(fields["pcesvn"], &t.PCESVN); err != nil {
+		return fmt.Errorf("invalid pcesvn: %w", err)
+	}
+
+	if raw, ok := fields["sgxtcbcomponents"]; ok {
+		var components []struct {
+			SVN int `json:"svn"`
+		}
+		if err := json.Unmarshal(raw, &components); err != nil {
+			return fmt.Errorf("invalid sgxtcbcomponents: %w", err)
+		}
+		if len(components) != len(t.SGXTCBComponentSVNs) {
+			return fmt.Errorf("invalid number of sgxtcbcomponents: %d", len(components))
+		}
+		for i, c := range components {
+			t.SGXTCBComponentSVNs[i] = c.SVN
+		}
+		return nil
+	}
+
+	for i := range t.SGXTCBComponentSVNs {
+		key := fmt.Sprintf("sgxtcbcomp%02dsvn", i+1)
+		if err := json.Unmarshal(fields[key], &t.SGXTCBComponentSVNs[i]); err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+type qeIdentity struct {
+	ID             string            `json:"id"`
+	Version        int               `json:"version"`
+	IssueDate      time.Time         `json:"issueDate"`
+	NextUpdate     time.Time         `json:"nextUpdate"`
+	MiscSelect     hexBytes          `json:"miscselect"`
+	MiscSelectMask hexBytes          `json:"miscselectMask"`
+	Attributes     hexBytes          `json:"attributes"`
+	AttributesMask hexBytes          `json:"attributesMask"`
+	MRSigner       hexBytes          `json:"mrsigner"`
+	ISVProdID      uint16            `json:"isvprodid"`
+	TCBLevels      []qeIdentityLevel `json:"tcbLevels"`
+}
+
+type qeIdentityLevel struct {
+	TCB struct {
+		ISVSVN uint16 `json:"isvsvn"`
+	} `json:"tcb"`
+	TCBStatus   string   `json:"tcbStatus"`
+	AdvisoryIDs []string `json:"advisoryIDs"`
+}
+
+type hexBytes []byte
+
+func (h *hexBytes) UnmarshalJSON(data []byte) error {
+	s, err := strconv.Unquote(string(data))
+	if err != nil {
+		return err
+	}
+	*h, err = hex.DecodeString(s)
+	return err
+}
+
+// LoadCollateral loads the collateral from the files in the directory.
+func LoadCollateral(dir string) (*Collateral, error) {
+	readFile := func(name string) ([]byte, error) {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read collateral: %w", err)
+		}
+		return data, nil
+	}
+
+	rootCAPEM, err := readFile(CollateralRootCAFileName)
+	if err != nil {
+		return nil, err
+	}
+	rawTCBInfo, err := readFile(CollateralTCBInfoFileName)
+	if err != nil {
+		return nil, err
+	}
+	tcbInfoIssuerChainPEM, err := readFile(CollateralTCBInfoIssuerChainFileName)
+	if err != nil {
+		return nil, err
+	}
+	rawQEIdentity, err := readFile(CollateralQEIdentityFileName)
+	if err != nil {
+		return nil, err
+	}
+	rootCACRL, err := readOptionalCRL(filepath.Join(dir, CollateralRootCACRLFileName))
+	if err != nil {
+		return nil, err
+	}
+	pckCRL, err := readOptionalCRL(filepath.Join(dir, CollateralPCKCRLFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCollateral(rootCAPEM, tcbInfoIssuerChainPEM, rawTCBInfo, rawQEIdentity, rootCACRL, pckCRL)
+}
+
+// NewCollateral parses the collateral. The CRLs can be nil.
+// The signatures of the TCB info and the QE identity are verified when a quote is verified.
+func NewCollateral(rootCAPEM, tcbInfoIssuerChainPEM, rawTCBInfo, rawQEIdentity []byte, rootCACRL, pckCRL *x509.RevocationList) (*Collateral, error) {
+	rootCADERs := decodePEMCertificates(rootCAPEM)
+	if len(rootCADERs) != 1 {
+		return nil, fmt.Errorf("the root CA must be a certificate in PEM")
+	}
+	rootCA, err := x509.ParseCertificate(rootCADERs[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse root CA: %w", err)
+	}
+	if err := checkRootCA(rootCA); err != nil {
+		return nil, err
+	}
+
+	tcbInfoIssuerChain, err := parseCertificates(decodePEMCertificates(tcbInfoIssuerChainPEM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TCB info issuer chain: %w", err)
+	}
+	if len(tcbInfoIssuerChain) == 0 {
+		return nil, errors.New("no certificate in TCB info issuer chain")
+	}
+
+	c := &Collateral{
+		RootCA:             rootCA,
+		TCBInfoIssuerChain: tcbInfoIssuerChain,
+		RawTCBInfo:         rawTCBInfo,
+		RawQEIdentity:      rawQEIdentity,
+		RootCACRL:          rootCACRL,
+		PCKCRL:             pckCRL,
+	}
+
+	var signedTCBInfo struct {
+		TCBInfo   json.RawMessage `json:"tcbInfo"`
+		Signature hexBytes        `json:"signature"`
+	}
+	if err := json.Unmarshal(rawTCBInfo, &signedTCBInfo); err != nil {
+		return nil, fmt.Errorf("failed to parse TCB info: %w", err)
+	}
+	if err := json.Unmarshal(signedTCBInfo.TCBInfo, &c.tcbInfo); err != nil {
+		return nil, fmt.Errorf("failed to parse TCB info: %w", err)
+	}
+	if c.tcbInfo.Version != 2 && c.tcbInfo.Version != 3 {
+		return nil, fmt.Errorf("unsupported TCB info version: %d", c.tcbInfo.Version)
+	}
+	c.tcbInfoSignature = signedTCBInfo.Signature
+
+	var signedQEIdentity struct {
+		EnclaveIdentity json.RawMessage `json:"enclaveIdentity"`
+		Signature       hexBytes        `json:"signature"`
+	}
+	if err := json.Unmarshal(rawQEIdentity, &signedQEIdentity); err != nil {
+		return nil, fmt.Errorf("failed to parse QE identity: %w", err)
+	}
+	if err := json.Unmarshal(signedQEIdentity.EnclaveIdentity, &c.qeIdentity); err != nil {
+		return nil, fmt.Errorf("failed to parse QE identity: %w", err)
+	}
+	if c.qeIdentity.ID != "QE" {
+		return nil, fmt.Errorf("not a QE identity: %s", c.qeIdentity.ID)
+	}
+	c.qeIdentitySignature = signedQEIdentity.Signature
+
+	// the signatures are verified over the exact bytes in the files
+	c.RawTCBInfo = signedTCBInfo.TCBInfo
+	c.RawQEIdentity = signedQEIdentity.EnclaveIdentity
+
+	return c, nil
+}
+
+// checkRootCA checks that the public key of the root CA is pinned.
+func checkRootCA(rootCA *x509.Certificate) error {
+	hash := sha256.Sum256(rootCA.RawSubjectPublicKeyInfo)
+	for _, trusted := range trustedRootCAPublicKeyHashes {
+		if hexEqual(trusted, hash[:]) {
+			return nil
+		}
+	}
+	return fmt.Errorf("the root CA is not the Intel SGX Root CA: public key hash %x", hash)
+}
+
+func parseCertificates(ders [][]byte) ([]*x509.Certificate, error) {
+	certs := make([]*x509.Certificate, 0, len(ders))
+	for _, der := range ders {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+func readOptionalCRL(path string) (*x509.RevocationList, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+	crl, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CRL %s: %w", path, err)
+	}
+	return crl, nil
+}
+
+// verifyECDSASignature verifies the signature in the raw format (r || s) of the SHA256 hash of the data.
+func verifyECDSASignature(pubKey *ecdsa.PublicKey, data, signature []byte) bool {
+	if len(signature) != ecdsaSignatureSize {
+		return false
+	}
+	hash := sha256.Sum256(data)
+	r := new(big.Int).SetBytes(signature[:ecdsaSignatureSize/2])
+	s := new(big.Int).SetBytes(signature[ecdsaSignatureSize/2:])
+	return ecdsa.Verify(pubKey, hash[:], r, s)
+}
@@ -0,0 +1,216 @@
+package sgx
+
+import (
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+const (
+	oeReportHeaderSize    = 16
+	oeReportHeaderVersion = 1
+	oeReportTypeSGXRemote = 2
+
+	quoteVersion3                 = 3
+	quoteAttKeyTypeECDSA          = 2
+	quoteHeaderSize               = 48
+	enclaveReportBodySize         = 384
+	ecdsaSignatureSize            = 64
+	ecdsaPublicKeySize            = 64
+	quoteCertDataTypePCKCertChain = 5
+)
+
+// enclaveReportBody is the SGX report body (sgx_report_body_t), which is included in a quote for the ISV enclave and the QE.
+type enclaveReportBody struct {
+	CPUSVN     []byte
+	MiscSelect uint32
+	Attributes []byte
+	MREnclave  []byte
+	MRSigner   []byte
+	ISVProdID  uint16
+	ISVSVN     uint16
+	ReportData []byte
+}
+
+// sgxQuote is a SGX ECDSA quote of version 3, which is generated by the quoting enclave (QE) with DCAP.
+type sgxQuote struct {
+	// raw header and report body, which are signed by the attestation key
+	signedData []byte
+
+	Version            uint16
+	AttestationKeyType uint16
+	QESVN              uint16
+	PCESVN             uint16
+
+	ReportBody        enclaveReportBody
+	ReportSignature   []byte
+	AttestationKey    []byte
+	QEReportBody      enclaveReportBody
+	rawQEReportBody   []byte
+	QEReportSignature []byte
+	QEAuthData        []byte
+	PCKCertChainPEM   []byte
+}
+
+// parseOEReport extracts the SGX quote from the remote report generated by ego (Open Enclave).
+// The report can also be a quote without the header of Open Enclave.
+func parseOEReport(reportBytes []byte) (*sgxQuote, error) {
+	if len(reportBytes) < oeReportHeaderSize {
+		return nil, errors.New("the report is too short")
+	}
+
+	// the quote starts with version 3 in 2 bytes, but the OE report starts with version 1 in 4 bytes.
+	if binary.LittleEndian.Uint32(reportBytes[0:4]) != oeReportHeaderVersion {
+		return parseSGXQuote(reportBytes)
+	}
+
+	reportType := binary.LittleEndian.Uint32(reportBytes[4:8])
+	if reportType != oeReportTypeSGXRemote {
+		return nil, fmt.Errorf("not a remote report. report type(%d)", reportType)
+	}
+	reportSize := binary.LittleEndian.Uint64(reportBytes[8:16])
+	if reportSize != uint64(len(reportBytes)-oeReportHeaderSize) {
+		return nil, fmt.Errorf("invalid report size. expected(%d), got(%d)", reportSize, len(reportBytes)-oeReportHeaderSize)
+	}
+
+	return parseSGXQuote(reportBytes[oeReportHeaderSize:])
+}
+
+func parseSGXQuote(quoteBytes []byte) (*sgxQuote, error) {
+	r := &byteReader{buf: quoteBytes}
+
+	signedData := r.peek(quoteHeaderSize + enclaveReportBodySize)
+
+	q := &sgxQuote{signedData: signedData}
+	q.Version = r.uint16()
+	q.AttestationKeyType = r.uint16()
+	r.skip(4) // TEE type
+	q.QESVN = r.uint16()
+	q.PCESVN = r.uint16()
+	r.skip(16 + 20) // QE vendor ID, user data
+	if r.err != nil {
+		return nil, fmt.Errorf("invalid quote header: %w", r.err)
+	}
+	if q.Version != quoteVersion3 {
+		return nil, fmt.Errorf("unsupported quote version: %d", q.Version)
+	}
+	if q.AttestationKeyType != quoteAttKeyTypeECDSA {
+		return nil, fmt.Errorf("unsupported attestation key type: %d", q.AttestationKeyType)
+	}
+
+	q.ReportBody = parseEnclaveReportBody(r)
+
+	signatureDataSize := r.uint32()
+	signatureData := r.bytes(int(signatureDataSize))
+	if r.err != nil {
+		return nil, fmt.Errorf("invalid quote: %w", r.err)
+	}
+	if r.remaining() != 0 {
+		return nil, fmt.Errorf("invalid quote: %d trailing bytes", r.remaining())
+	}
+
+	sr := &byteReader{buf: signatureData}
+	q.ReportSignature = sr.bytes(ecdsaSignatureSize)
+	q.AttestationKey = sr.bytes(ecdsaPublicKeySize)
+	q.rawQEReportBody = sr.peek(enclaveReportBodySize)
+	q.QEReportBody = parseEnclaveReportBody(sr)
+	q.QEReportSignature = sr.bytes(ecdsaSignatureSize)
+	q.QEAuthData = sr.bytes(int(sr.uint16()))
+	certDataType := sr.uint16()
+	certData := sr.bytes(int(sr.uint32()))
+	if sr.err != nil {
+		return nil, fmt.Errorf("invalid quote signature data: %w", sr.err)
+	}
+	if certDataType != quoteCertDataTypePCKCertChain {
+		return nil, fmt.Errorf("unsupported certification data type: %d", certDataType)
+	}
+	// the PCK cert chain can be terminated by a null character
+	q.PCKCertChainPEM = certData
+
+	return q, nil
+}
+
+func parseEnclaveReportBody(r *byteReader) enclaveReportBody {
+	var body enclaveReportBody
+	body.CPUSVN = r.bytes(16)
+	body.MiscSelect = r.uint32()
+	r.skip(12 + 16) // reserved, ISV ext prod ID
+	body.Attributes = r.bytes(16)
+	body.MREnclave = r.bytes(32)
+	r.skip(32)
+	body.MRSigner = r.bytes(32)
+	r.skip(32 + 64) // reserved, config ID
+	body.ISVProdID = r.uint16()
+	body.ISVSVN = r.uint16()
+	r.skip(2 + 42 + 16) // config SVN, reserved, ISV family ID
+	body.ReportData = r.bytes(64)
+	return body
+}
+
+// decodePEMCertificates returns DER bytes of all certificates in the PEM data.
+func decodePEMCertificates(data []byte) [][]byte {
+	var ders [][]byte
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return ders
+		}
+		if block.Type == "CERTIFICATE" {
+			ders = append(ders, block.Bytes)
+		}
+	}
+}
+
+// byteReader reads little-endian values sequentially.
+// Once an error occurs, all subsequent reads return zero values and the error is kept.
+type byteReader struct {
+	buf []byte
+	pos int
+	err error
+}
+
+func (r *byteReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.pos+n > len(r.buf) {
+		r.err = fmt.Errorf("unexpected end of data at offset %d", r.pos)
+		return nil
+	}
+	b := r.buf[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *byteReader) peek(n int) []byte {
+	if r.err != nil || r.pos+n > len(r.buf) {
+		return nil
+	}
+	return r.buf[r.pos : r.pos+n]
+}
+
+func (r *byteReader) skip(n int) {
+	r.bytes(n)
+}
+
+func (r *byteReader) uint16() uint16 {
+	b := r.bytes(2)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(b)
+}
+
+func (r *byteReader) uint32() uint32 {
+	b := r.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+func (r *byteReader) remaining() int {
+	return len(r.buf) - r.pos
+}
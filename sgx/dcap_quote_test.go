@@ -0,0 +1,143 @@
+package sgx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The structs below are copied field by field from the headers of the Intel SGX SDK (sgx_report.h, sgx_quote_3.h)
+// and Open Enclave (oe_report_header_t), so that the offsets in the parser are checked against them.
+
+type specReportBody struct {
+	CPUSVN       [16]byte
+	MiscSelect   uint32
+	Reserved1    [12]byte
+	ISVExtProdID [16]byte
+	Attributes   [16]byte
+	MREnclave    [32]byte
+	Reserved2    [32]byte
+	MRSigner     [32]byte
+	Reserved3    [32]byte
+	ConfigID     [64]byte
+	ISVProdID    uint16
+	ISVSVN       uint16
+	ConfigSVN    uint16
+	Reserved4    [42]byte
+	ISVFamilyID  [16]byte
+	ReportData   [64]byte
+}
+
+type specQuoteHeader struct {
+	Version     uint16
+	AttKeyType  uint16
+	AttKeyData0 uint32
+	QESVN       uint16
+	PCESVN      uint16
+	VendorID    [16]byte
+	UserData    [20]byte
+}
+
+type specOEReportHeader struct {
+	Version    uint32
+	ReportType uint32
+	ReportSize uint64
+}
+
+func filled(b byte, n int) []byte {
+	return bytes.Repeat([]byte{b}, n)
+}
+
+// newSpecReportBody returns a report body whose fields have distinct values, so that any wrong offset is detected.
+func newSpecReportBody(seed byte) specReportBody {
+	var body specReportBody
+	copy(body.CPUSVN[:], filled(seed+1, 16))
+	body.MiscSelect = uint32(seed) + 2
+	copy(body.Reserved1[:], filled(0xe1, 12))
+	copy(body.ISVExtProdID[:], filled(0xe2, 16))
+	copy(body.Attributes[:], filled(seed+3, 16))
+	copy(body.MREnclave[:], filled(seed+4, 32))
+	copy(body.Reserved2[:], filled(0xe3, 32))
+	copy(body.MRSigner[:], filled(seed+5, 32))
+	copy(body.Reserved3[:], filled(0xe4, 32))
+	copy(body.ConfigID[:], filled(0xe5, 64))
+	body.ISVProdID = uint16(seed) + 6
+	body.ISVSVN = uint16(seed) + 7
+	body.ConfigSVN = 0xe6e6
+	copy(body.Reserved4[:], filled(0xe7, 42))
+	copy(body.ISVFamilyID[:], filled(0xe8, 16))
+	copy(body.ReportData[:], filled(seed+8, 64))
+	return body
+}
+
+func requireReportBody(t *testing.T, expected specReportBody, actual enclaveReportBody) {
+	require.Equal(t, expected.CPUSVN[:], actual.CPUSVN)
+	require.Equal(t, expected.MiscSelect, actual.MiscSelect)
+	require.Equal(t, expected.Attributes[:], actual.Attributes)
+	require.Equal(t, expected.MREnclave[:], actual.MREnclave)
+	require.Equal(t, expected.MRSigner[:], actual.MRSigner)
+	require.Equal(t, expected.ISVProdID, actual.ISVProdID)
+	require.Equal(t, expected.ISVSVN, actual.ISVSVN)
+	require.Equal(t, expected.ReportData[:], actual.ReportData)
+}
+
+func TestParseOEReportLayout(t *testing.T) {
+	write := func(buf *bytes.Buffer, v interface{}) {
+		require.NoError(t, binary.Write(buf, binary.LittleEndian, v))
+	}
+
+	header := specQuoteHeader{Version: 3, AttKeyType: 2, AttKeyData0: 0xe9e9e9e9, QESVN: 0x0102, PCESVN: 0x0304}
+	copy(header.VendorID[:], filled(0xea, 16))
+	copy(header.UserData[:], filled(0xeb, 20))
+	body := newSpecReportBody(0x10)
+	qeBody := newSpecReportBody(0x20)
+	qeAuthData := []byte("qe-auth-data")
+	certData := []byte("cert-data")
+
+	var sigData bytes.Buffer
+	sigData.Write(filled(0x31, ecdsaSignatureSize))
+	sigData.Write(filled(0x32, ecdsaPublicKeySize))
+	write(&sigData, qeBody)
+	sigData.Write(filled(0x33, ecdsaSignatureSize))
+	write(&sigData, uint16(len(qeAuthData)))
+	sigData.Write(qeAuthData)
+	write(&sigData, uint16(quoteCertDataTypePCKCertChain))
+	write(&sigData, uint32(len(certData)))
+	sigData.Write(certData)
+
+	var quote bytes.Buffer
+	write(&quote, header)
+	write(&quote, body)
+	write(&quote, uint32(sigData.Len()))
+	quote.Write(sigData.Bytes())
+
+	var report bytes.Buffer
+	write(&report, specOEReportHeader{Version: 1, ReportType: 2, ReportSize: uint64(quote.Len())})
+	report.Write(quote.Bytes())
+
+	require.Equal(t, quoteHeaderSize, binary.Size(header))
+	require.Equal(t, enclaveReportBodySize, binary.Size(body))
+	require.Equal(t, oeReportHeaderSize, binary.Size(specOEReportHeader{}))
+
+	for _, bz := range [][]byte{report.Bytes(), quote.Bytes()} {
+		q, err := parseOEReport(bz)
+		require.NoError(t, err)
+
+		require.Equal(t, header.Version, q.Version)
+		require.Equal(t, header.AttKeyType, q.AttestationKeyType)
+		require.Equal(t, header.QESVN, q.QESVN)
+		require.Equal(t, header.PCESVN, q.PCESVN)
+		require.Equal(t, quote.Bytes()[:quoteHeaderSize+enclaveReportBodySize], q.signedData)
+		requireReportBody(t, body, q.ReportBody)
+
+		require.Equal(t, filled(0x31, ecdsaSignatureSize), q.ReportSignature)
+		require.Equal(t, filled(0x32, ecdsaPublicKeySize), q.AttestationKey)
+		requireReportBody(t, qeBody, q.QEReportBody)
+		require.Equal(t, sigData.Bytes()[ecdsaSignatureSize+ecdsaPublicKeySize:][:enclaveReportBodySize], q.rawQEReportBody)
+		require.Equal(t, filled(0x33, ecdsaSignatureSize), q.QEReportSignature)
+		require.Equal(t, qeAuthData, q.QEAuthData)
+		require.Equal(t, certData, q.PCKCertChainPEM)
+	}
+}
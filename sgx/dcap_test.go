@@ -0,0 +1,499 @@
+package sgx_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgelesssys/ego/attestation"
+	"github.com/edgelesssys/ego/attestation/tcbstatus"
+	"github.com/medibloc/panacea-doracle/sgx"
+	"github.com/stretchr/testify/require"
+)
+
+// Real quotes can be generated only on SGX machines registered to Intel,
+// so the quotes and the collateral in these tests are generated by a test PKI which imitates Intel's.
+// Its root CA is trusted only in tests by sgx.TrustRootCAForTest.
+
+var (
+	testFMSPC      = []byte{0x00, 0x90, 0x6e, 0xa1, 0x00, 0x00}
+	testPCEID      = []byte{0x00, 0x00}
+	testQEMRSigner = make32(0x8c)
+	testMREnclave  = make32(0x01)
+	testMRSigner   = make32(0x02)
+)
+
+func make32(b byte) []byte {
+	bz := make([]byte, 32)
+	for i := range bz {
+		bz[i] = b
+	}
+	return bz
+}
+
+type dcapFixture struct {
+	now time.Time
+
+	rootKey     *ecdsa.PrivateKey
+	rootCA      *x509.Certificate
+	pckCAKey    *ecdsa.PrivateKey
+	pckCA       *x509.Certificate
+	pckKey      *ecdsa.PrivateKey
+	pckCert     *x509.Certificate
+	tcbKey      *ecdsa.PrivateKey
+	tcbCert     *x509.Certificate
+	attKey      *ecdsa.PrivateKey
+	platformSVN int
+}
+
+func newDCAPFixture(t *testing.T) *dcapFixture {
+	f := &dcapFixture{now: time.Now(), platformSVN: 5}
+
+	f.rootKey, f.rootCA = newTestCert(t, "Intel SGX Root CA", nil, nil, 1, true, nil)
+	sgx.TrustRootCAForTest(t, f.rootCA)
+	f.pckCAKey, f.pckCA = newTestCert(t, "Intel SGX PCK Platform CA", f.rootCA, f.rootKey, 2, true, nil)
+	f.tcbKey, f.tcbCert = newTestCert(t, "Intel SGX TCB Signing", f.rootCA, f.rootKey, 3, false, nil)
+	f.issuePCKCert(t)
+
+	var err error
+	f.attKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return f
+}
+
+// issuePCKCert issues the PCK certificate with the TCB of the platform.
+func (f *dcapFixture) issuePCKCert(t *testing.T) {
+	type sgxExtension struct {
+		ID    asn1.ObjectIdentifier
+		Value asn1.RawValue
+	}
+	mustMarshal := func(v interface{}) asn1.RawValue {
+		bz, err := asn1.Marshal(v)
+		require.NoError(t, err)
+		return asn1.RawValue{FullBytes: bz}
+	}
+
+	tcbOID := asn1.ObjectIdentifier{1, 2, 840, 113741, 1, 13, 1, 2}
+	var tcbComponents []sgxExtension
+	for i := 1; i <= 16; i++ {
+		tcbComponents = append(tcbComponents, sgxExtension{append(append(asn1.ObjectIdentifier{}, tcbOID...), i), mustMarshal(f.platformSVN)})
+	}
+	tcbComponents = append(tcbComponents,
+		sgxExtension{append(append(asn1.ObjectIdentifier{}, tcbOID...), 17), mustMarshal(11)},
+		sgxExtension{append(append(asn1.ObjectIdentifier{}, tcbOID...), 18), mustMarshal(make([]byte, 16))},
+	)
+
+	extensions := mustMarshal([]sgxExtension{
+		{asn1.ObjectIdentifier{1, 2, 840, 113741, 1, 13, 1, 1}, mustMarshal(make([]byte, 16))},
+		{tcbOID, mustMarshal(tcbComponents)},
+		{asn1.ObjectIdentifier{1, 2, 840, 113741, 1, 13, 1, 3}, mustMarshal(testPCEID)},
+		{asn1.ObjectIdentifier{1, 2, 840, 113741, 1, 13, 1, 4}, mustMarshal(testFMSPC)},
+	})
+
+	f.pckKey, f.pckCert = newTestCert(t, "Intel SGX PCK Certificate", f.pckCA, f.pckCAKey, 4, false, []pkix.Extension{
+		{Id: asn1.ObjectIdentifier{1, 2, 840, 113741, 1, 13, 1}, Value: extensions.FullBytes},
+	})
+}
+
+func newTestCert(t *testing.T, cn string, issuer *x509.Certificate, issuerKey *ecdsa.PrivateKey, serial int64, isCA bool, extensions []pkix.Extension) (*ecdsa.PrivateKey, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		ExtraExtensions:       extensions,
+	}
+	if issuer == nil {
+		issuer, issuerKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, issuerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return key, cert
+}
+
+func encodePEM(certs ...*x509.Certificate) []byte {
+	var bz []byte
+	for _, cert := range certs {
+		bz = append(bz, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	return bz
+}
+
+func signRaw(t *testing.T, key *ecdsa.PrivateKey, data []byte) []byte {
+	hash := sha256.Sum256(data)
+	r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+	require.NoError(t, err)
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return sig
+}
+
+// signedJSON returns the JSON in the format of the Intel PCS, like {"tcbInfo":{...},"signature":"..."}.
+func (f *dcapFixture) signedJSON(t *testing.T, field string, body interface{}) []byte {
+	raw, err := json.Marshal(body)
+	require.NoError(t, err)
+	bz, err := json.Marshal(map[string]interface{}{
+		field:       json.RawMessage(raw),
+		"signature": hex.EncodeToString(signRaw(t, f.tcbKey, raw)),
+	})
+	require.NoError(t, err)
+	return bz
+}
+
+func (f *dcapFixture) tcbInfo(t *testing.T) []byte {
+	tcbLevel := func(svn int, status string, advisoryIDs ...string) map[string]interface{} {
+		var components []map[string]int
+		for i := 0; i < 16; i++ {
+			components = append(components, map[string]int{"svn": svn})
+		}
+		return map[string]interface{}{
+			"tcb":         map[string]interface{}{"sgxtcbcomponents": components, "pcesvn": 11},
+			"tcbDate":     "2022-08-10T00:00:00Z",
+			"tcbStatus":   status,
+			"advisoryIDs": advisoryIDs,
+		}
+	}
+	return f.signedJSON(t, "tcbInfo", map[string]interface{}{
+		"id":         "SGX",
+		"version":    3,
+		"issueDate":  f.now.Add(-time.Hour).UTC().Format(time.RFC3339),
+		"nextUpdate": f.now.Add(time.Hour).UTC().Format(time.RFC3339),
+		"fmspc":      hex.EncodeToString(testFMSPC),
+		"pceId":      hex.EncodeToString(testPCEID),
+		"tcbLevels": []interface{}{
+			tcbLevel(5, "UpToDate"),
+			tcbLevel(3, "SWHardeningNeeded", "INTEL-SA-00615"),
+			tcbLevel(0, "OutOfDate", "INTEL-SA-00334", "INTEL-SA-00615"),
+		},
+	})
+}
+
+func (f *dcapFixture) qeIdentity(t *testing.T) []byte {
+	return f.signedJSON(t, "enclaveIdentity", map[string]interface{}{
+		"id":             "QE",
+		"version":        2,
+		"issueDate":      f.now.Add(-time.Hour).UTC().Format(time.RFC3339),
+		"nextUpdate":     f.now.Add(time.Hour).UTC().Format(time.RFC3339),
+		"miscselect":     "00000000",
+		"miscselectMask": "FFFFFFFF",
+		"attributes":     "11000000000000000000000000000000",
+		"attributesMask": "FBFFFFFFFFFFFFFF0000000000000000",
+		"mrsigner":       hex.EncodeToString(testQEMRSigner),
+		"isvprodid":      1,
+		"tcbLevels": []interface{}{
+			map[string]interface{}{"tcb": map[string]int{"isvsvn": 6}, "tcbStatus": "UpToDate"},
+		},
+	})
+}
+
+func (f *dcapFixture) collateral(t *testing.T) *sgx.Collateral {
+	collateral, err := sgx.NewCollateral(encodePEM(f.rootCA), encodePEM(f.tcbCert, f.rootCA), f.tcbInfo(t), f.qeIdentity(t), nil, nil)
+	require.NoError(t, err)
+	return collateral
+}
+
+// reportBody returns the SGX report body (sgx_report_body_t).
+func reportBody(mrEnclave, mrSigner []byte, attributes byte, isvProdID, isvSVN uint16, reportData []byte) []byte {
+	body := make([]byte, 384)
+	body[48] = attributes
+	copy(body[64:96], mrEnclave)
+	copy(body[128:160], mrSigner)
+	binary.LittleEndian.PutUint16(body[256:258], isvProdID)
+	binary.LittleEndian.PutUint16(body[258:260], isvSVN)
+	copy(body[320:384], reportData)
+	return body
+}
+
+// quote returns the remote report of ego, which is a quote with the header of Open Enclave.
+func (f *dcapFixture) quote(t *testing.T, data []byte) []byte {
+	header := make([]byte, 48)
+	binary.LittleEndian.PutUint16(header[0:2], 3)
+	binary.LittleEndian.PutUint16(header[2:4], 2)
+
+	reportData := make([]byte, 64)
+	copy(reportData, data)
+	body := reportBody(testMREnclave, testMRSigner, 0x04, 1, 2, reportData)
+
+	attKey := make([]byte, 64)
+	f.attKey.X.FillBytes(attKey[:32])
+	f.attKey.Y.FillBytes(attKey[32:])
+	qeAuthData := []byte("qe-auth-data")
+
+	qeReportData := sha256.Sum256(append(append([]byte{}, attKey...), qeAuthData...))
+	qeReport := reportBody(make32(0x03), testQEMRSigner, 0x11, 1, 6, qeReportData[:])
+
+	certData := encodePEM(f.pckCert, f.pckCA, f.rootCA)
+
+	var sigData []byte
+	sigData = append(sigData, signRaw(t, f.attKey, append(append([]byte{}, header...), body...))...)
+	sigData = append(sigData, attKey...)
+	sigData = append(sigData, qeReport...)
+	sigData = append(sigData, signRaw(t, f.pckKey, qeReport)...)
+	sigData = binary.LittleEndian.AppendUint16(sigData, uint16(len(qeAuthData)))
+	sigData = append(sigData, qeAuthData...)
+	sigData = binary.LittleEndian.AppendUint16(sigData, 5)
+	sigData = binary.LittleEndian.AppendUint32(sigData, uint32(len(certData)))
+	sigData = append(sigData, certData...)
+
+	var quote []byte
+	quote = append(quote, header...)
+	quote = append(quote, body...)
+	quote = binary.LittleEndian.AppendUint32(quote, uint32(len(sigData)))
+	quote = append(quote, sigData...)
+
+	report := make([]byte, 16)
+	binary.LittleEndian.PutUint32(report[0:4], 1)
+	binary.LittleEndian.PutUint32(report[4:8], 2)
+	binary.LittleEndian.PutUint64(report[8:16], uint64(len(quote)))
+	return append(report, quote...)
+}
+
+func TestDCAPVerifier(t *testing.T) {
+	f := newDCAPFixture(t)
+	verifier := sgx.NewDCAPVerifier(f.collateral(t))
+
+	report, advisoryIDs, err := verifier.VerifyRemoteReportWithAdvisories(f.quote(t, []byte("hello")))
+	require.NoError(t, err)
+	require.Empty(t, advisoryIDs)
+	require.Equal(t, tcbstatus.UpToDate, report.TCBStatus)
+	require.Equal(t, testMREnclave, report.UniqueID)
+	require.Equal(t, testMRSigner, report.SignerID)
+	require.Equal(t, []byte{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, report.ProductID)
+	require.Equal(t, uint(2), report.SecurityVersion)
+	require.False(t, report.Debug)
+	require.Equal(t, []byte("hello"), report.Data[:5])
+}
+
+func TestDCAPVerifierOutdatedPlatform(t *testing.T) {
+	f := newDCAPFixture(t)
+	f.platformSVN = 4
+	f.issuePCKCert(t)
+	verifier := sgx.NewDCAPVerifier(f.collateral(t))
+
+	report, advisoryIDs, err := verifier.VerifyRemoteReportWithAdvisories(f.quote(t, []byte("hello")))
+	require.ErrorIs(t, err, attestation.ErrTCBLevelInvalid)
+	require.Equal(t, tcbstatus.SWHardeningNeeded, report.TCBStatus)
+	require.Equal(t, []string{"INTEL-SA-00615"}, advisoryIDs)
+
+	// the advisory IDs are included in the result of the policy
+	policy := sgx.NewExactTrustPolicy(*sgx.NewEnclaveInfo(report.ProductID, testMRSigner, testMREnclave))
+	policy.MinSecurityVersion = 2
+	policy.WarnTCBStatuses = []tcbstatus.Status{tcbstatus.SWHardeningNeeded}
+	result, err := policy.WithVerifier(verifier).Verify(f.quote(t, []byte("hello")), []byte("hello"))
+	require.NoError(t, err)
+	require.Equal(t, sgx.TCBActionWarn, result.TCBAction)
+	require.Equal(t, []string{"INTEL-SA-00615"}, result.TCBAdvisoryIDs)
+}
+
+func TestDCAPVerifierInvalidQuote(t *testing.T) {
+	f := newDCAPFixture(t)
+	verifier := sgx.NewDCAPVerifier(f.collateral(t))
+
+	// tamper the report data
+	quote := f.quote(t, []byte("hello"))
+	quote[16+48+320] ^= 0xff
+	_, err := verifier.VerifyRemoteReport(quote)
+	require.ErrorContains(t, err, "invalid signature of the enclave report")
+
+	_, err = verifier.VerifyRemoteReport(quote[:100])
+	require.Error(t, err)
+
+	_, err = verifier.VerifyRemoteReport(nil)
+	require.ErrorIs(t, err, attestation.ErrEmptyReport)
+}
+
+func TestDCAPVerifierUntrustedRootCA(t *testing.T) {
+	f := newDCAPFixture(t)
+	other := newDCAPFixture(t)
+
+	// the quote is issued by another root CA
+	_, err := sgx.NewDCAPVerifier(f.collateral(t)).VerifyRemoteReport(other.quote(t, []byte("hello")))
+	require.ErrorContains(t, err, "invalid PCK certificate chain")
+
+	// the collateral is signed by another root CA
+	collateral, err := sgx.NewCollateral(encodePEM(f.rootCA), encodePEM(other.tcbCert), f.tcbInfo(t), f.qeIdentity(t), nil, nil)
+	require.NoError(t, err)
+	_, err = sgx.NewDCAPVerifier(collateral).VerifyRemoteReport(f.quote(t, []byte("hello")))
+	require.ErrorContains(t, err, "invalid TCB info issuer chain")
+}
+
+func TestCollateralRootCA(t *testing.T) {
+	f := newDCAPFixture(t)
+
+	// the certificate downloaded from https://certificates.trustedservices.intel.com/Intel_SGX_Provisioning_Certification_RootCA.pem
+	intelRootCAPEM, err := os.ReadFile(filepath.Join("testdata", "intel_sgx_root_ca.pem"))
+	require.NoError(t, err)
+	block, _ := pem.Decode(intelRootCAPEM)
+	require.NotNil(t, block)
+	intelRootCA, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	require.NoError(t, intelRootCA.CheckSignatureFrom(intelRootCA))
+	hash := sha256.Sum256(intelRootCA.RawSubjectPublicKeyInfo)
+	require.Equal(t, sgx.IntelSGXRootCAPublicKeySHA256, hex.EncodeToString(hash[:]))
+
+	_, err = sgx.NewCollateral(intelRootCAPEM, encodePEM(f.tcbCert, f.rootCA), f.tcbInfo(t), f.qeIdentity(t), nil, nil)
+	require.NoError(t, err)
+
+	// a root CA which is not pinned, even if it has the same name
+	_, untrustedRootCA := newTestCert(t, "Intel SGX Root CA", nil, nil, 1, true, nil)
+	_, err = sgx.NewCollateral(encodePEM(untrustedRootCA), encodePEM(f.tcbCert, f.rootCA), f.tcbInfo(t), f.qeIdentity(t), nil, nil)
+	require.ErrorContains(t, err, "not the Intel SGX Root CA")
+}
+
+func TestDCAPVerifierCollateralSignedByOtherCert(t *testing.T) {
+	f := newDCAPFixture(t)
+	quote := f.quote(t, []byte("hello"))
+
+	for name, signer := range map[string]struct {
+		key   *ecdsa.PrivateKey
+		chain []*x509.Certificate
+	}{
+		// the PCK key can be extracted from an outdated platform
+		"PCK certificate": {f.pckKey, []*x509.Certificate{f.pckCert, f.pckCA, f.rootCA}},
+		"PCK CA":          {f.pckCAKey, []*x509.Certificate{f.pckCA, f.rootCA}},
+	} {
+		tcbKey := f.tcbKey
+		f.tcbKey = signer.key
+		collateral, err := sgx.NewCollateral(encodePEM(f.rootCA), encodePEM(signer.chain...), f.tcbInfo(t), f.qeIdentity(t), nil, nil)
+		f.tcbKey = tcbKey
+		require.NoError(t, err, name)
+
+		_, err = sgx.NewDCAPVerifier(collateral).VerifyRemoteReport(quote)
+		require.ErrorContains(t, err, "invalid TCB info issuer chain", name)
+	}
+
+	// a certificate with the name of the TCB signing certificate, but not issued by the root CA directly
+	otherKey, otherCert := newTestCert(t, "Intel SGX TCB Signing", f.pckCA, f.pckCAKey, 5, false, nil)
+	f.tcbKey = otherKey
+	collateral, err := sgx.NewCollateral(encodePEM(f.rootCA), encodePEM(otherCert, f.pckCA, f.rootCA), f.tcbInfo(t), f.qeIdentity(t), nil, nil)
+	require.NoError(t, err)
+	_, err = sgx.NewDCAPVerifier(collateral).VerifyRemoteReport(quote)
+	require.ErrorContains(t, err, "must be issued by the root CA directly")
+}
+
+// TestDCAPVerifierRecordedReports verifies the remote reports recorded from real oracles with the collateral of the Intel PCS.
+// Each directory in testdata/recorded has:
+//   - attestation.json: the response of GET /v0/attestation of an oracle running on an SGX machine
+//   - verified_at.txt: the time (RFC3339) when the collateral was valid
+//   - the collateral files loaded by sgx.LoadCollateral
+func TestDCAPVerifierRecordedReports(t *testing.T) {
+	dirs, err := filepath.Glob(filepath.Join("testdata", "recorded", "*", "attestation.json"))
+	require.NoError(t, err)
+	if len(dirs) == 0 {
+		t.Skip("no recorded report in testdata/recorded")
+	}
+
+	for _, path := range dirs {
+		dir := filepath.Dir(path)
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			bz, err := os.ReadFile(path)
+			require.NoError(t, err)
+			var resp struct {
+				RemoteReportBase64 string `json:"remote_report_base64"`
+				Enclave            struct {
+					UniqueID  string `json:"unique_id"`
+					SignerID  string `json:"signer_id"`
+					ProductID string `json:"product_id"`
+				} `json:"enclave"`
+			}
+			require.NoError(t, json.Unmarshal(bz, &resp))
+			report, err := base64.StdEncoding.DecodeString(resp.RemoteReportBase64)
+			require.NoError(t, err)
+
+			verifiedAtBz, err := os.ReadFile(filepath.Join(dir, "verified_at.txt"))
+			require.NoError(t, err)
+			verifiedAt, err := time.Parse(time.RFC3339, strings.TrimSpace(string(verifiedAtBz)))
+			require.NoError(t, err)
+
+			collateral, err := sgx.LoadCollateral(dir)
+			require.NoError(t, err)
+			verifier := sgx.NewDCAPVerifier(collateral).WithTime(verifiedAt)
+
+			parsed, err := verifier.VerifyRemoteReport(report)
+			if err != nil {
+				// the TCB of the recorded platform can be out of date
+				require.ErrorIs(t, err, attestation.ErrTCBLevelInvalid)
+			}
+			require.Equal(t, resp.Enclave.UniqueID, hex.EncodeToString(parsed.UniqueID))
+			require.Equal(t, resp.Enclave.SignerID, hex.EncodeToString(parsed.SignerID))
+			require.Equal(t, resp.Enclave.ProductID, hex.EncodeToString(parsed.ProductID))
+
+			// the collateral is not valid long after it was recorded
+			_, err = sgx.NewDCAPVerifier(collateral).WithTime(verifiedAt.AddDate(1, 0, 0)).VerifyRemoteReport(report)
+			require.ErrorContains(t, err, "expired")
+		})
+	}
+}
+
+func TestDCAPVerifierExpiredCollateral(t *testing.T) {
+	f := newDCAPFixture(t)
+	verifier := sgx.NewDCAPVerifier(f.collateral(t)).WithTime(f.now.Add(2 * time.Hour))
+
+	_, err := verifier.VerifyRemoteReport(f.quote(t, []byte("hello")))
+	require.ErrorContains(t, err, "expired")
+}
+
+func TestDCAPVerifierRevokedPCKCert(t *testing.T) {
+	f := newDCAPFixture(t)
+
+	crlDER, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: f.now.Add(-time.Hour),
+		NextUpdate: f.now.Add(time.Hour),
+		RevokedCertificates: []pkix.RevokedCertificate{
+			{SerialNumber: f.pckCert.SerialNumber, RevocationTime: f.now.Add(-time.Minute)},
+		},
+	}, f.pckCA, f.pckCAKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		sgx.CollateralRootCAFileName:             encodePEM(f.rootCA),
+		sgx.CollateralTCBInfoFileName:            f.tcbInfo(t),
+		sgx.CollateralTCBInfoIssuerChainFileName: encodePEM(f.tcbCert, f.rootCA),
+		sgx.CollateralQEIdentityFileName:         f.qeIdentity(t),
+	}
+	for name, data := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0600))
+	}
+
+	// the CRL is optional
+	collateral, err := sgx.LoadCollateral(dir)
+	require.NoError(t, err)
+	_, err = sgx.NewDCAPVerifier(collateral).VerifyRemoteReport(f.quote(t, []byte("hello")))
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, sgx.CollateralPCKCRLFileName), crlDER, 0600))
+	collateral, err = sgx.LoadCollateral(dir)
+	require.NoError(t, err)
+	_, err = sgx.NewDCAPVerifier(collateral).VerifyRemoteReport(f.quote(t, []byte("hello")))
+	require.ErrorContains(t, err, "revoked")
+	require.False(t, errors.Is(err, attestation.ErrTCBLevelInvalid))
+}
@@ -0,0 +1,458 @@
+package sgx
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/edgelesssys/ego/attestation"
+	"github.com/edgelesssys/ego/attestation/tcbstatus"
+)
+
+var (
+	oidSGXExtensions = asn1.ObjectIdentifier{1, 2, 840, 113741, 1, 13, 1}
+	oidSGXTCB        = asn1.ObjectIdentifier{1, 2, 840, 113741, 1, 13, 1, 2}
+	oidSGXPCEID      = asn1.ObjectIdentifier{1, 2, 840, 113741, 1, 13, 1, 3}
+	oidSGXFMSPC      = asn1.ObjectIdentifier{1, 2, 840, 113741, 1, 13, 1, 4}
+	oidSGXPCESVN     = asn1.ObjectIdentifier{1, 2, 840, 113741, 1, 13, 1, 2, 17}
+)
+
+// tcbSigningCertCommonName is the common name of the certificate which signs the TCB info and the QE identity.
+const tcbSigningCertCommonName = "Intel SGX TCB Signing"
+
+var _ ReportVerifier = (*DCAPVerifier)(nil)
+
+// DCAPVerifier verifies SGX ECDSA quotes (DCAP) in pure Go with the collateral given in advance,
+// so that reports can be verified in any environment without SGX and the Intel quote provider library.
+//
+// It verifies that:
+//   - the PCK certificate chain in the quote is issued by the root CA in the collateral, and not revoked by the CRLs if given,
+//   - the QE report is signed by the PCK certificate, and binds the attestation key,
+//   - the enclave report is signed by the attestation key,
+//   - the TCB info and the QE identity are signed by the TCB signing certificate issued by the root CA,
+//   - the QE matches the QE identity,
+//
+// and evaluates the TCB status of the platform by the TCB info and the QE identity.
+type DCAPVerifier struct {
+	collateral *Collateral
+	now        func() time.Time
+}
+
+// NewDCAPVerifier creates a DCAPVerifier with the collateral.
+func NewDCAPVerifier(collateral *Collateral) *DCAPVerifier {
+	return &DCAPVerifier{
+		collateral: collateral,
+		now:        time.Now,
+	}
+}
+
+// WithTime returns a copy of the verifier which verifies the validity of certificates and the collateral at the time,
+// e.g. to audit quotes recorded in the past.
+func (v DCAPVerifier) WithTime(t time.Time) *DCAPVerifier {
+	v.now = func() time.Time { return t }
+	return &v
+}
+
+// VerifyRemoteReport verifies the report and returns its content.
+// Like ego, if the TCB status is not up-to-date, the report is returned with attestation.ErrTCBLevelInvalid.
+func (v *DCAPVerifier) VerifyRemoteReport(reportBytes []byte) (attestation.Report, error) {
+	report, _, err := v.VerifyRemoteReportWithAdvisories(reportBytes)
+	return report, err
+}
+
+// VerifyRemoteReportWithAdvisories is like VerifyRemoteReport, but also returns the IDs of Intel security advisories
+// affecting the TCB level of the platform.
+func (v *DCAPVerifier) VerifyRemoteReportWithAdvisories(reportBytes []byte) (attestation.Report, []string, error) {
+	if len(reportBytes) == 0 {
+		return attestation.Report{}, nil, attestation.ErrEmptyReport
+	}
+
+	quote, err := parseOEReport(reportBytes)
+	if err != nil {
+		return attestation.Report{}, nil, err
+	}
+
+	status, advisoryIDs, err := v.verifyQuote(quote)
+	if err != nil {
+		return attestation.Report{}, nil, err
+	}
+
+	report := attestation.Report{
+		Data:            quote.ReportBody.ReportData,
+		SecurityVersion: uint(quote.ReportBody.ISVSVN),
+		Debug:           quote.ReportBody.Attributes[0]&0x02 != 0,
+		UniqueID:        quote.ReportBody.MREnclave,
+		SignerID:        quote.ReportBody.MRSigner,
+		ProductID:       isvProdIDBytes(quote.ReportBody.ISVProdID),
+		TCBStatus:       status,
+	}
+	if status != tcbstatus.UpToDate {
+		return report, advisoryIDs, attestation.ErrTCBLevelInvalid
+	}
+	return report, advisoryIDs, nil
+}
+
+// isvProdIDBytes returns the product ID in the same format as ego, which is little-endian in 16 bytes.
+func isvProdIDBytes(isvProdID uint16) []byte {
+	productID := make([]byte, 16)
+	binary.LittleEndian.PutUint16(productID, isvProdID)
+	return productID
+}
+
+func (v *DCAPVerifier) verifyQuote(quote *sgxQuote) (tcbstatus.Status, []string, error) {
+	now := v.now()
+
+	pckChain, err := v.verifyPCKCertChain(quote.PCKCertChainPEM, now)
+	if err != nil {
+		return 0, nil, err
+	}
+	pckPubKey, ok := pckChain[0].PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return 0, nil, errors.New("the PCK certificate doesn't have an ECDSA public key")
+	}
+
+	if !verifyECDSASignature(pckPubKey, quote.rawQEReportBody, quote.QEReportSignature) {
+		return 0, nil, errors.New("invalid signature of the QE report")
+	}
+
+	// the QE report binds the attestation key, which signs the enclave report
+	expectedQEReportData := sha256.Sum256(append(append([]byte{}, quote.AttestationKey...), quote.QEAuthData...))
+	if !bytes.Equal(quote.QEReportBody.ReportData[:32], expectedQEReportData[:]) ||
+		!bytes.Equal(quote.QEReportBody.ReportData[32:], make([]byte, 32)) {
+		return 0, nil, errors.New("the attestation key is not bound to the QE report")
+	}
+
+	attestationKey := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(quote.AttestationKey[:32]),
+		Y:     new(big.Int).SetBytes(quote.AttestationKey[32:]),
+	}
+	if !attestationKey.Curve.IsOnCurve(attestationKey.X, attestationKey.Y) {
+		return 0, nil, errors.New("invalid attestation key")
+	}
+	if !verifyECDSASignature(attestationKey, quote.signedData, quote.ReportSignature) {
+		return 0, nil, errors.New("invalid signature of the enclave report")
+	}
+
+	if err := v.verifyCollateral(now); err != nil {
+		return 0, nil, err
+	}
+
+	pckTCB, err := parsePCKCertTCB(pckChain[0])
+	if err != nil {
+		return 0, nil, err
+	}
+	platformStatus, platformAdvisoryIDs, err := v.platformTCBStatus(pckTCB)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	qeStatus, qeAdvisoryIDs, err := v.qeTCBStatus(quote.QEReportBody)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return combineTCBStatus(platformStatus, qeStatus), append(platformAdvisoryIDs, qeAdvisoryIDs...), nil
+}
+
+// verifyPCKCertChain verifies the PCK certificate chain in the quote, and returns the chain from the PCK certificate.
+func (v *DCAPVerifier) verifyPCKCertChain(chainPEM []byte, now time.Time) ([]*x509.Certificate, error) {
+	certs, err := parseCertificates(decodePEMCertificates(chainPEM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PCK certificate chain: %w", err)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no PCK certificate in the quote")
+	}
+
+	chain, err := v.verifyCertificate(certs[0], certs[1:], now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PCK certificate chain: %w", err)
+	}
+
+	// the PCK certificate is issued by the PCK platform/processor CA, which is issued by the root CA.
+	if v.collateral.PCKCRL != nil && len(chain) >= 2 {
+		if err := checkRevocation(v.collateral.PCKCRL, chain[1], chain[0], now); err != nil {
+			return nil, err
+		}
+	}
+	return chain, nil
+}
+
+// verifyCertificate verifies that the certificate is issued by the root CA in the collateral,
+// and that no intermediate CA is revoked by the root CA CRL.
+func (v *DCAPVerifier) verifyCertificate(cert *x509.Certificate, intermediates []*x509.Certificate, now time.Time) ([]*x509.Certificate, error) {
+	roots := x509.NewCertPool()
+	roots.AddCert(v.collateral.RootCA)
+	intermediatePool := x509.NewCertPool()
+	for _, c := range intermediates {
+		intermediatePool.AddCert(c)
+	}
+
+	chains, err := cert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediatePool,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, err
+	}
+	chain := chains[0]
+
+	if v.collateral.RootCACRL != nil {
+		// all certificates issued by the root CA, except the root CA itself
+		for _, c := range chain[:len(chain)-1] {
+			if bytes.Equal(c.RawIssuer, v.collateral.RootCA.RawSubject) {
+				if err := checkRevocation(v.collateral.RootCACRL, v.collateral.RootCA, c, now); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return chain, nil
+}
+
+func checkRevocation(crl *x509.RevocationList, issuer, cert *x509.Certificate, now time.Time) error {
+	if err := crl.CheckSignatureFrom(issuer); err != nil {
+		return fmt.Errorf("invalid CRL of %s: %w", issuer.Subject.CommonName, err)
+	}
+	if !crl.NextUpdate.IsZero() && now.After(crl.NextUpdate) {
+		return fmt.Errorf("the CRL of %s is expired at %s", issuer.Subject.CommonName, crl.NextUpdate)
+	}
+	for _, revoked := range crl.RevokedCertificates {
+		if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+			return fmt.Errorf("the certificate is revoked: %s", cert.Subject.CommonName)
+		}
+	}
+	return nil
+}
+
+// verifyCollateral verifies that the TCB info and the QE identity are signed by the TCB signing certificate,
+// and that they are not expired.
+func (v *DCAPVerifier) verifyCollateral(now time.Time) error {
+	c := v.collateral
+
+	chain, err := v.verifyCertificate(c.TCBInfoIssuerChain[0], c.TCBInfoIssuerChain[1:], now)
+	if err != nil {
+		return fmt.Errorf("invalid TCB info issuer chain: %w", err)
+	}
+	// other certificates issued under the root CA, like PCK certificates, must not sign the collateral.
+	// otherwise, the key extracted from an outdated platform could sign a TCB info which says the platform is up to date.
+	if err := checkTCBSigningCert(chain); err != nil {
+		return fmt.Errorf("invalid TCB info issuer chain: %w", err)
+	}
+	signingKey, ok := chain[0].PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return errors.New("the TCB signing certificate doesn't have an ECDSA public key")
+	}
+
+	if !verifyECDSASignature(signingKey, c.RawTCBInfo, c.tcbInfoSignature) {
+		return errors.New("invalid signature of the TCB info")
+	}
+	if now.After(c.tcbInfo.NextUpdate) {
+		return fmt.Errorf("the TCB info is expired at %s", c.tcbInfo.NextUpdate)
+	}
+
+	if !verifyECDSASignature(signingKey, c.RawQEIdentity, c.qeIdentitySignature) {
+		return errors.New("invalid signature of the QE identity")
+	}
+	if now.After(c.qeIdentity.NextUpdate) {
+		return fmt.Errorf("the QE identity is expired at %s", c.qeIdentity.NextUpdate)
+	}
+
+	return nil
+}
+
+// checkTCBSigningCert checks that the chain is the TCB signing certificate issued directly by the root CA.
+func checkTCBSigningCert(chain []*x509.Certificate) error {
+	if len(chain) != 2 {
+		return fmt.Errorf("the TCB signing certificate must be issued by the root CA directly, but the chain has %d certificates", len(chain))
+	}
+	cert := chain[0]
+	if cert.Subject.CommonName != tcbSigningCertCommonName {
+		return fmt.Errorf("not the TCB signing certificate: %s", cert.Subject.CommonName)
+	}
+	if cert.IsCA {
+		return errors.New("the TCB signing certificate must not be a CA")
+	}
+	if cert.KeyUsage != 0 && cert.KeyUsage&x509.KeyUsageDigitalSignature == 0 {
+		return errors.New("the TCB signing certificate is not for digital signatures")
+	}
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidSGXExtensions) {
+			return errors.New("the TCB signing certificate must not have SGX extensions")
+		}
+	}
+	return nil
+}
+
+// pckCertTCB is the TCB of the platform in the SGX extensions of the PCK certificate.
+type pckCertTCB struct {
+	FMSPC               []byte
+	PCEID               []byte
+	SGXTCBComponentSVNs [16]int
+	PCESVN              int
+}
+
+type asn1SGXExtension struct {
+	ID    asn1.ObjectIdentifier
+	Value asn1.RawValue
+}
+
+func parsePCKCertTCB(cert *x509.Certificate) (*pckCertTCB, error) {
+	var sgxExtensions []asn1SGXExtension
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidSGXExtensions) {
+			if _, err := asn1.Unmarshal(ext.Value, &sgxExtensions); err != nil {
+				return nil, fmt.Errorf("invalid SGX extensions in the PCK certificate: %w", err)
+			}
+		}
+	}
+	if sgxExtensions == nil {
+		return nil, errors.New("no SGX extensions in the PCK certificate")
+	}
+
+	tcb := &pckCertTCB{}
+	var foundTCB bool
+	for _, ext := range sgxExtensions {
+		switch {
+		case ext.ID.Equal(oidSGXFMSPC):
+			tcb.FMSPC = ext.Value.Bytes
+		case ext.ID.Equal(oidSGXPCEID):
+			tcb.PCEID = ext.Value.Bytes
+		case ext.ID.Equal(oidSGXTCB):
+			var components []asn1SGXExtension
+			if _, err := asn1.Unmarshal(ext.Value.FullBytes, &components); err != nil {
+				return nil, fmt.Errorf("invalid TCB in the PCK certificate: %w", err)
+			}
+			for _, component := range components {
+				if len(component.ID) != len(oidSGXTCB)+1 || !component.ID[:len(oidSGXTCB)].Equal(oidSGXTCB) {
+					continue
+				}
+				index := component.ID[len(oidSGXTCB)]
+				if index > 17 {
+					// CPUSVN is not used, because its components are given separately
+					continue
+				}
+				var svn int
+				if _, err := asn1.Unmarshal(component.Value.FullBytes, &svn); err != nil {
+					return nil, fmt.Errorf("invalid TCB component in the PCK certificate: %w", err)
+				}
+				if component.ID.Equal(oidSGXPCESVN) {
+					tcb.PCESVN = svn
+				} else if index >= 1 {
+					tcb.SGXTCBComponentSVNs[index-1] = svn
+				}
+			}
+			foundTCB = true
+		}
+	}
+	if tcb.FMSPC == nil || tcb.PCEID == nil || !foundTCB {
+		return nil, errors.New("FMSPC, PCEID or TCB is missing in the PCK certificate")
+	}
+	return tcb, nil
+}
+
+// platformTCBStatus returns the status of the first TCB level in the TCB info, which is lower than or equal to the TCB of the platform.
+func (v *DCAPVerifier) platformTCBStatus(tcb *pckCertTCB) (tcbstatus.Status, []string, error) {
+	info := v.collateral.tcbInfo
+
+	if !hexEqual(info.FMSPC, tcb.FMSPC) {
+		return 0, nil, fmt.Errorf("the TCB info is not for the FMSPC of the platform: %X", tcb.FMSPC)
+	}
+	if !hexEqual(info.PCEID, tcb.PCEID) {
+		return 0, nil, fmt.Errorf("the TCB info is not for the PCEID of the platform: %X", tcb.PCEID)
+	}
+
+	for _, level := range info.TCBLevels {
+		if isTCBLevelLowerOrEqual(level.TCB, tcb) {
+			status, err := parseTCBStatus(level.TCBStatus)
+			if err != nil {
+				return 0, nil, err
+			}
+			return status, level.AdvisoryIDs, nil
+		}
+	}
+	return 0, nil, errors.New("no TCB level in the TCB info matches the platform")
+}
+
+func isTCBLevelLowerOrEqual(level tcbLevelTCB, tcb *pckCertTCB) bool {
+	for i, svn := range level.SGXTCBComponentSVNs {
+		if tcb.SGXTCBComponentSVNs[i] < svn {
+			return false
+		}
+	}
+	return tcb.PCESVN >= level.PCESVN
+}
+
+// qeTCBStatus verifies that the QE matches the QE identity, and returns the status of its TCB level.
+func (v *DCAPVerifier) qeTCBStatus(qe enclaveReportBody) (tcbstatus.Status, []string, error) {
+	identity := v.collateral.qeIdentity
+
+	if len(identity.MiscSelect) != 4 || len(identity.MiscSelectMask) != 4 {
+		return 0, nil, errors.New("invalid miscselect in the QE identity")
+	}
+	mask := binary.BigEndian.Uint32(identity.MiscSelectMask)
+	if qe.MiscSelect&mask != binary.BigEndian.Uint32(identity.MiscSelect)&mask {
+		return 0, nil, errors.New("the miscselect of the QE doesn't match the QE identity")
+	}
+
+	if len(identity.Attributes) != len(qe.Attributes) || len(identity.AttributesMask) != len(qe.Attributes) {
+		return 0, nil, errors.New("invalid attributes in the QE identity")
+	}
+	for i := range qe.Attributes {
+		if qe.Attributes[i]&identity.AttributesMask[i] != identity.Attributes[i]&identity.AttributesMask[i] {
+			return 0, nil, errors.New("the attributes of the QE don't match the QE identity")
+		}
+	}
+
+	if !bytes.Equal(qe.MRSigner, identity.MRSigner) {
+		return 0, nil, errors.New("the signer of the QE doesn't match the QE identity")
+	}
+	if qe.ISVProdID != identity.ISVProdID {
+		return 0, nil, errors.New("the product ID of the QE doesn't match the QE identity")
+	}
+
+	for _, level := range identity.TCBLevels {
+		if qe.ISVSVN >= level.TCB.ISVSVN {
+			status, err := parseTCBStatus(level.TCBStatus)
+			if err != nil {
+				return 0, nil, err
+			}
+			return status, level.AdvisoryIDs, nil
+		}
+	}
+	return 0, nil, errors.New("no TCB level in the QE identity matches the QE")
+}
+
+// combineTCBStatus combines the TCB statuses of the platform and the QE, as the Intel quote verification library does.
+func combineTCBStatus(platform, qe tcbstatus.Status) tcbstatus.Status {
+	switch qe {
+	case tcbstatus.Revoked:
+		return tcbstatus.Revoked
+	case tcbstatus.OutOfDate:
+		switch platform {
+		case tcbstatus.UpToDate, tcbstatus.SWHardeningNeeded:
+			return tcbstatus.OutOfDate
+		case tcbstatus.ConfigurationNeeded, tcbstatus.ConfigurationAndSWHardeningNeeded:
+			return tcbstatus.OutOfDateConfigurationNeeded
+		}
+	}
+	return platform
+}
+
+func hexEqual(hexString string, b []byte) bool {
+	decoded, err := hex.DecodeString(hexString)
+	return err == nil && bytes.Equal(decoded, b)
+}
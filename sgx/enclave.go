@@ -0,0 +1,104 @@
+package sgx
+
+import (
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/edgelesssys/ego/attestation"
+	"github.com/medibloc/panacea-doracle/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const simulationSealKeyFileName = "simulation_seal_key"
+
+// Enclave provides the functions of the TEE that the oracle depends on.
+type Enclave interface {
+	// GetRemoteReport generates a report containing the data for use in remote attestation.
+	GetRemoteReport(data []byte) ([]byte, error)
+	// VerifyRemoteReport verifies the report and returns its content.
+	VerifyRemoteReport(reportBytes []byte) (attestation.Report, error)
+	// GetSelfReport returns the report of this enclave, without generating a remote report.
+	GetSelfReport() (attestation.Report, error)
+	// SealWithUniqueKey seals the data with a key derived from the unique ID of the enclave.
+	SealWithUniqueKey(data []byte) ([]byte, error)
+	// SealWithProductKey seals the data with a key derived from the signer ID and the product ID of the enclave,
+	// so that it can be unsealed by other versions of the enclave.
+	SealWithProductKey(data []byte) ([]byte, error)
+	// Unseal unseals the data sealed by this enclave with any key.
+	Unseal(data []byte) ([]byte, error)
+}
+
+var (
+	currentEnclave      Enclave = egoEnclave{}
+	currentEnclaveMutex sync.RWMutex
+)
+
+// SetEnclave sets the enclave used by all functions in this package.
+func SetEnclave(e Enclave) {
+	currentEnclaveMutex.Lock()
+	defer currentEnclaveMutex.Unlock()
+
+	currentEnclave = e
+}
+
+// CurrentEnclave returns the enclave used by all functions in this package.
+// If SetEnclave is not called, the ego enclave running on SGX is used.
+func CurrentEnclave() Enclave {
+	currentEnclaveMutex.RLock()
+	defer currentEnclaveMutex.RUnlock()
+
+	return currentEnclave
+}
+
+// InitEnclave sets the enclave and the seal policy selected by the config.
+func InitEnclave(conf *config.Config) error {
+	if err := SetSealPolicy(conf.Enclave.SealPolicy); err != nil {
+		return err
+	}
+
+	switch conf.Enclave.Mode {
+	case config.EnclaveModeSGX:
+		SetEnclave(egoEnclave{})
+		return nil
+	case config.EnclaveModeSimulation:
+		// this is also checked while reading the config, but check it again not to run the simulation on the mainnet in any case.
+		if config.IsMainnetChainID(conf.Panacea.ChainID) {
+			return fmt.Errorf("the simulated enclave cannot be used for the mainnet chain: %s", conf.Panacea.ChainID)
+		}
+
+		info, err := simulationEnclaveInfo(conf)
+		if err != nil {
+			return err
+		}
+
+		e, err := NewSimulatedEnclave(filepath.Join(conf.AbsDataDirPath(), simulationSealKeyFileName), *info)
+		if err != nil {
+			return fmt.Errorf("failed to create simulated enclave: %w", err)
+		}
+
+		log.Warn("the oracle is running in the simulated enclave. no data is protected by SGX, so never use it in production")
+		SetEnclave(e)
+		return nil
+	default:
+		return fmt.Errorf("invalid enclave mode: %s", conf.Enclave.Mode)
+	}
+}
+
+func simulationEnclaveInfo(conf *config.Config) (*EnclaveInfo, error) {
+	uniqueID, err := hex.DecodeString(conf.Enclave.SimulationUniqueID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid simulation unique ID: %w", err)
+	}
+	signerID, err := hex.DecodeString(conf.Enclave.SimulationSignerID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid simulation signer ID: %w", err)
+	}
+	productID, err := hex.DecodeString(conf.Enclave.SimulationProductID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid simulation product ID: %w", err)
+	}
+
+	return NewEnclaveInfo(productID, signerID, uniqueID), nil
+}
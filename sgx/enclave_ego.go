@@ -0,0 +1,37 @@
+package sgx
+
+import (
+	"github.com/edgelesssys/ego/attestation"
+	"github.com/edgelesssys/ego/ecrypto"
+	"github.com/edgelesssys/ego/enclave"
+)
+
+var _ Enclave = egoEnclave{}
+
+// egoEnclave is the enclave running on SGX using ego.
+// This works only in the SGX-FLC environment where the SGX quote provider is installed.
+type egoEnclave struct{}
+
+func (egoEnclave) GetRemoteReport(data []byte) ([]byte, error) {
+	return enclave.GetRemoteReport(data)
+}
+
+func (egoEnclave) VerifyRemoteReport(reportBytes []byte) (attestation.Report, error) {
+	return enclave.VerifyRemoteReport(reportBytes)
+}
+
+func (egoEnclave) GetSelfReport() (attestation.Report, error) {
+	return enclave.GetSelfReport()
+}
+
+func (egoEnclave) SealWithUniqueKey(data []byte) ([]byte, error) {
+	return ecrypto.SealWithUniqueKey(data, nil)
+}
+
+func (egoEnclave) SealWithProductKey(data []byte) ([]byte, error) {
+	return ecrypto.SealWithProductKey(data, nil)
+}
+
+func (egoEnclave) Unseal(data []byte) ([]byte, error) {
+	return ecrypto.Unseal(data, nil)
+}
@@ -0,0 +1,195 @@
+package sgx
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/edgelesssys/ego/attestation"
+	"github.com/edgelesssys/ego/attestation/tcbstatus"
+	"github.com/edgelesssys/ego/ecrypto"
+)
+
+const (
+	simulationSealKeySize = 32
+	// reportDataSize is the size of the report data in a SGX report
+	reportDataSize = 64
+)
+
+var _ Enclave = (*SimulatedEnclave)(nil)
+
+// SimulatedEnclave is an enclave for development and testing without SGX.
+// It seals data with keys derived from a key stored in a plain file, and generates mock reports self-signed by a key derived from it.
+// So, it doesn't protect any data, and its reports prove nothing.
+type SimulatedEnclave struct {
+	info         EnclaveInfo
+	sealKey      []byte
+	uniqueKey    []byte
+	productKey   []byte
+	reportKey    ed25519.PrivateKey
+	reportPubKey ed25519.PublicKey
+}
+
+// simulatedReport is a mock report signed by the simulated enclave which generated it.
+type simulatedReport struct {
+	Body      []byte `json:"body"`
+	PubKey    []byte `json:"pub_key"`
+	Signature []byte `json:"signature"`
+}
+
+type simulatedReportBody struct {
+	Data            []byte `json:"data"`
+	SecurityVersion uint   `json:"security_version"`
+	UniqueID        []byte `json:"unique_id"`
+	SignerID        []byte `json:"signer_id"`
+	ProductID       []byte `json:"product_id"`
+}
+
+// NewSimulatedEnclave creates a simulated enclave with the seal key in the file.
+// If the file doesn't exist, a new seal key is generated and written to the file.
+func NewSimulatedEnclave(sealKeyPath string, info EnclaveInfo) (*SimulatedEnclave, error) {
+	sealKey, err := loadOrCreateSimulationSealKey(sealKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	reportKeySeed := sha256.Sum256(append([]byte("simulated-report-key"), sealKey...))
+	reportKey := ed25519.NewKeyFromSeed(reportKeySeed[:])
+
+	uniqueKey := sha256.Sum256(bytes.Join([][]byte{[]byte("simulated-unique-key"), sealKey, info.UniqueID}, nil))
+	productKey := sha256.Sum256(bytes.Join([][]byte{[]byte("simulated-product-key"), sealKey, info.SignerID, info.ProductID}, nil))
+
+	return &SimulatedEnclave{
+		info:         info,
+		sealKey:      sealKey,
+		uniqueKey:    uniqueKey[:],
+		productKey:   productKey[:],
+		reportKey:    reportKey,
+		reportPubKey: reportKey.Public().(ed25519.PublicKey),
+	}, nil
+}
+
+func loadOrCreateSimulationSealKey(path string) ([]byte, error) {
+	sealKey, err := os.ReadFile(path)
+	if err == nil {
+		if len(sealKey) != simulationSealKeySize {
+			return nil, fmt.Errorf("invalid size of simulation seal key in %s: %d", path, len(sealKey))
+		}
+		return sealKey, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	sealKey = make([]byte, simulationSealKeySize)
+	if _, err := rand.Read(sealKey); err != nil {
+		return nil, fmt.Errorf("failed to generate simulation seal key: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create dir of %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, sealKey, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return sealKey, nil
+}
+
+func (e *SimulatedEnclave) GetRemoteReport(data []byte) ([]byte, error) {
+	if len(data) > reportDataSize {
+		return nil, fmt.Errorf("report data must not be longer than %d bytes", reportDataSize)
+	}
+
+	// the report data is padded like a SGX report
+	reportData := make([]byte, reportDataSize)
+	copy(reportData, data)
+
+	body, err := json.Marshal(simulatedReportBody{
+		Data:            reportData,
+		SecurityVersion: PromisedMinSecurityVersion,
+		UniqueID:        e.info.UniqueID,
+		SignerID:        e.info.SignerID,
+		ProductID:       e.info.ProductID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(simulatedReport{
+		Body:      body,
+		PubKey:    e.reportPubKey,
+		Signature: ed25519.Sign(e.reportKey, body),
+	})
+}
+
+// VerifyRemoteReport verifies that the report was signed by the key included in it.
+// Because the report is self-signed, it doesn't prove that the report was generated by a genuine enclave.
+func (e *SimulatedEnclave) VerifyRemoteReport(reportBytes []byte) (attestation.Report, error) {
+	if len(reportBytes) == 0 {
+		return attestation.Report{}, attestation.ErrEmptyReport
+	}
+
+	var report simulatedReport
+	if err := json.Unmarshal(reportBytes, &report); err != nil {
+		return attestation.Report{}, fmt.Errorf("not a simulated report: %w", err)
+	}
+	if len(report.PubKey) != ed25519.PublicKeySize {
+		return attestation.Report{}, errors.New("invalid public key in the simulated report")
+	}
+	if !ed25519.Verify(report.PubKey, report.Body, report.Signature) {
+		return attestation.Report{}, errors.New("invalid signature of the simulated report")
+	}
+
+	var body simulatedReportBody
+	if err := json.Unmarshal(report.Body, &body); err != nil {
+		return attestation.Report{}, fmt.Errorf("invalid body of the simulated report: %w", err)
+	}
+
+	return attestation.Report{
+		Data:            body.Data,
+		SecurityVersion: body.SecurityVersion,
+		Debug:           true,
+		UniqueID:        body.UniqueID,
+		SignerID:        body.SignerID,
+		ProductID:       body.ProductID,
+		TCBStatus:       tcbstatus.UpToDate,
+	}, nil
+}
+
+func (e *SimulatedEnclave) GetSelfReport() (attestation.Report, error) {
+	return attestation.Report{
+		SecurityVersion: PromisedMinSecurityVersion,
+		Debug:           true,
+		UniqueID:        e.info.UniqueID,
+		SignerID:        e.info.SignerID,
+		ProductID:       e.info.ProductID,
+		TCBStatus:       tcbstatus.UpToDate,
+	}, nil
+}
+
+func (e *SimulatedEnclave) SealWithUniqueKey(data []byte) ([]byte, error) {
+	return ecrypto.Encrypt(data, e.uniqueKey, nil)
+}
+
+func (e *SimulatedEnclave) SealWithProductKey(data []byte) ([]byte, error) {
+	return ecrypto.Encrypt(data, e.productKey, nil)
+}
+
+// Unseal tries all keys, because the sealed data doesn't record which key sealed it.
+// The seal key itself is tried for the data sealed before the keys were derived by the unique ID and the product ID.
+func (e *SimulatedEnclave) Unseal(data []byte) ([]byte, error) {
+	var err error
+	for _, key := range [][]byte{e.uniqueKey, e.productKey, e.sealKey} {
+		var unsealed []byte
+		if unsealed, err = ecrypto.Decrypt(data, key, nil); err == nil {
+			return unsealed, nil
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,90 @@
+package sgx_test
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/medibloc/panacea-doracle/config"
+	"github.com/medibloc/panacea-doracle/sgx"
+	"github.com/stretchr/testify/require"
+)
+
+func newSimulatedEnclave(t *testing.T, sealKeyPath string) *sgx.SimulatedEnclave {
+	info := sgx.NewEnclaveInfo([]byte{1}, []byte("signer-id"), []byte("unique-id"))
+	e, err := sgx.NewSimulatedEnclave(sealKeyPath, *info)
+	require.NoError(t, err)
+	return e
+}
+
+// useEnclave sets the enclave of the sgx package during the test
+func useEnclave(t *testing.T, e sgx.Enclave) {
+	prev := sgx.CurrentEnclave()
+	sgx.SetEnclave(e)
+	t.Cleanup(func() { sgx.SetEnclave(prev) })
+}
+
+func TestSimulatedEnclaveSeal(t *testing.T) {
+	sealKeyPath := filepath.Join(t.TempDir(), "seal_key")
+	e := newSimulatedEnclave(t, sealKeyPath)
+
+	sealed, err := e.SealWithUniqueKey([]byte("hello"))
+	require.NoError(t, err)
+	require.NotContains(t, string(sealed), "hello")
+
+	// the seal key is persisted, so the data can be unsealed after restart
+	unsealed, err := newSimulatedEnclave(t, sealKeyPath).Unseal(sealed)
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), unsealed)
+
+	// the data cannot be unsealed with another seal key
+	_, err = newSimulatedEnclave(t, filepath.Join(t.TempDir(), "seal_key")).Unseal(sealed)
+	require.Error(t, err)
+}
+
+func TestSimulatedEnclaveRemoteReport(t *testing.T) {
+	useEnclave(t, newSimulatedEnclave(t, filepath.Join(t.TempDir(), "seal_key")))
+
+	data := []byte("hello")
+	report, err := sgx.GenerateRemoteReport(data)
+	require.NoError(t, err)
+
+	enclaveInfo, err := sgx.GetSelfEnclaveInfo()
+	require.NoError(t, err)
+	require.Equal(t, []byte("unique-id"), enclaveInfo.UniqueID)
+
+	require.NoError(t, sgx.VerifyRemoteReport(report, data, *enclaveInfo))
+	require.Error(t, sgx.VerifyRemoteReport(report, []byte("wrong"), *enclaveInfo))
+
+	parsed, err := sgx.ParseRemoteReport(report)
+	require.NoError(t, err)
+	require.True(t, parsed.Debug)
+
+	// a tampered report cannot be verified
+	var tampered map[string][]byte
+	require.NoError(t, json.Unmarshal(report, &tampered))
+	tampered["body"] = append(tampered["body"][:len(tampered["body"])-1], ' ', '}')
+	tamperedBz, err := json.Marshal(tampered)
+	require.NoError(t, err)
+	_, err = sgx.ParseRemoteReport(tamperedBz)
+	require.ErrorContains(t, err, "invalid signature")
+
+	_, err = sgx.GenerateRemoteReport(make([]byte, 65))
+	require.Error(t, err)
+}
+
+func TestInitSimulatedEnclave(t *testing.T) {
+	useEnclave(t, sgx.CurrentEnclave())
+
+	conf := config.DefaultConfig()
+	conf.SetHomeDir(t.TempDir())
+	conf.Enclave.Mode = config.EnclaveModeSimulation
+
+	// the simulated enclave is refused for the mainnet
+	conf.Panacea.ChainID = "panacea-3"
+	require.ErrorContains(t, sgx.InitEnclave(conf), "mainnet")
+
+	conf.Panacea.ChainID = "testing"
+	require.NoError(t, sgx.InitEnclave(conf))
+	require.IsType(t, &sgx.SimulatedEnclave{}, sgx.CurrentEnclave())
+}
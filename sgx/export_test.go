@@ -0,0 +1,16 @@
+package sgx
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"testing"
+)
+
+// TrustRootCAForTest accepts the root CA of a test PKI in the collateral until the test ends.
+func TrustRootCAForTest(t testing.TB, rootCA *x509.Certificate) {
+	hash := sha256.Sum256(rootCA.RawSubjectPublicKeyInfo)
+	original := trustedRootCAPublicKeyHashes
+	trustedRootCAPublicKeyHashes = append(append([]string{}, original...), hex.EncodeToString(hash[:]))
+	t.Cleanup(func() { trustedRootCAPublicKeyHashes = original })
+}
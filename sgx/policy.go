@@ -0,0 +1,233 @@
+package sgx
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/edgelesssys/ego/attestation"
+	"github.com/edgelesssys/ego/attestation/tcbstatus"
+	"github.com/medibloc/panacea-doracle/config"
+)
+
+// ReportVerifier verifies remote reports. The Enclave and the DCAPVerifier are ReportVerifiers.
+type ReportVerifier interface {
+	// VerifyRemoteReport verifies the report and returns its content.
+	// If the TCB status is not up-to-date, the report is returned with attestation.ErrTCBLevelInvalid.
+	VerifyRemoteReport(reportBytes []byte) (attestation.Report, error)
+}
+
+// advisoryReportVerifier is a ReportVerifier which also provides the IDs of Intel security advisories.
+type advisoryReportVerifier interface {
+	ReportVerifier
+	VerifyRemoteReportWithAdvisories(reportBytes []byte) (attestation.Report, []string, error)
+}
+
+// TrustPolicy defines which enclaves are trusted by remote attestation.
+// An empty list of IDs doesn't trust any enclave.
+type TrustPolicy struct {
+	SignerIDs          [][]byte
+	ProductIDs         [][]byte
+	UniqueIDs          [][]byte
+	MinSecurityVersion uint
+	// TCBStatuses are accepted silently, and WarnTCBStatuses are accepted with a warning.
+	// Other TCB statuses are rejected.
+	TCBStatuses     []tcbstatus.Status
+	WarnTCBStatuses []tcbstatus.Status
+	// Verifier verifies reports before they are checked by the policy. If nil, the current enclave is used.
+	Verifier ReportVerifier
+}
+
+// NewTrustPolicy creates a TrustPolicy from the config.
+// If signer IDs or product IDs are not set in the config, only the ones of the self enclave are trusted.
+// If unique IDs are not set in the config, they should be set by WithDefaultUniqueIDs, e.g. from the chain.
+func NewTrustPolicy(conf config.EnclaveConfig, self *EnclaveInfo) (*TrustPolicy, error) {
+	signerIDs, err := decodeHexIDs(conf.TrustedSignerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted signer IDs: %w", err)
+	}
+	if len(signerIDs) == 0 {
+		signerIDs = [][]byte{self.SignerID}
+	}
+
+	productIDs, err := decodeHexIDs(conf.TrustedProductIDs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted product IDs: %w", err)
+	}
+	if len(productIDs) == 0 {
+		productIDs = [][]byte{self.ProductID}
+	}
+
+	uniqueIDs, err := decodeHexIDs(conf.TrustedUniqueIDs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted unique IDs: %w", err)
+	}
+
+	tcbStatuses, err := ParseTCBStatuses(conf.AllowedTCBStatuses)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowed TCB statuses: %w", err)
+	}
+
+	warnTCBStatuses, err := ParseTCBStatuses(conf.WarnTCBStatuses)
+	if err != nil {
+		return nil, fmt.Errorf("invalid warn TCB statuses: %w", err)
+	}
+
+	return &TrustPolicy{
+		SignerIDs:          signerIDs,
+		ProductIDs:         productIDs,
+		UniqueIDs:          uniqueIDs,
+		MinSecurityVersion: conf.MinSecurityVersion,
+		TCBStatuses:        tcbStatuses,
+		WarnTCBStatuses:    warnTCBStatuses,
+	}, nil
+}
+
+// NewExactTrustPolicy creates a TrustPolicy which trusts only the enclave,
+// with the promised security version and the up-to-date TCB.
+func NewExactTrustPolicy(info EnclaveInfo) *TrustPolicy {
+	return &TrustPolicy{
+		SignerIDs:          [][]byte{info.SignerID},
+		ProductIDs:         [][]byte{info.ProductID},
+		UniqueIDs:          [][]byte{info.UniqueID},
+		MinSecurityVersion: PromisedMinSecurityVersion,
+		TCBStatuses:        []tcbstatus.Status{tcbstatus.UpToDate},
+	}
+}
+
+// WithDefaultUniqueIDs returns a copy of the policy which trusts the unique IDs,
+// only if no unique ID is set in the policy (e.g. by the config).
+func (p TrustPolicy) WithDefaultUniqueIDs(uniqueIDs ...[]byte) *TrustPolicy {
+	if len(p.UniqueIDs) == 0 {
+		p.UniqueIDs = uniqueIDs
+	}
+	return &p
+}
+
+// WithVerifier returns a copy of the policy which verifies reports by the verifier, instead of the current enclave.
+func (p TrustPolicy) WithVerifier(verifier ReportVerifier) *TrustPolicy {
+	p.Verifier = verifier
+	return &p
+}
+
+// Verify verifies whether the report was properly generated by an enclave trusted by the policy,
+// and whether the report contains the expected data.
+// It returns the result including the content of the report, so that the caller can check other details.
+// If the report is parsed but rejected by the policy, the result is returned with the error for logging.
+func (p TrustPolicy) Verify(reportBytes, expectedData []byte) (*VerificationResult, error) {
+	report, advisoryIDs, err := p.verifyReport(reportBytes)
+	// the report is returned with ErrTCBLevelInvalid, so that its TCB status can be checked by the policy
+	if err != nil && !errors.Is(err, attestation.ErrTCBLevelInvalid) {
+		return nil, err
+	}
+
+	result := &VerificationResult{
+		Report:         report,
+		TCBAction:      p.tcbAction(report.TCBStatus),
+		TCBAdvisoryIDs: advisoryIDs,
+	}
+
+	switch result.TCBAction {
+	case TCBActionReject:
+		return result, fmt.Errorf("TCB status is not allowed: %s", report.TCBStatus)
+	case TCBActionWarn:
+		result.Warnings = append(result.Warnings, fmt.Sprintf("TCB status is accepted with warning: %s", report.TCBStatus))
+	}
+
+	if report.SecurityVersion < p.MinSecurityVersion {
+		return result, fmt.Errorf("invalid security version in the report. min(%d), got(%d)", p.MinSecurityVersion, report.SecurityVersion)
+	}
+	if !containsID(p.ProductIDs, report.ProductID) {
+		return result, fmt.Errorf("invalid product ID in the report: %X", report.ProductID)
+	}
+	if !containsID(p.SignerIDs, report.SignerID) {
+		return result, fmt.Errorf("invalid signer ID in the report: %X", report.SignerID)
+	}
+	if !containsID(p.UniqueIDs, report.UniqueID) {
+		return result, fmt.Errorf("invalid unique ID in the report: %X", report.UniqueID)
+	}
+	if len(report.Data) < len(expectedData) || !bytes.Equal(report.Data[:len(expectedData)], expectedData) {
+		return result, fmt.Errorf("invalid data in the report. expected(%s)", base64.StdEncoding.EncodeToString(expectedData))
+	}
+
+	return result, nil
+}
+
+func (p TrustPolicy) verifyReport(reportBytes []byte) (attestation.Report, []string, error) {
+	switch verifier := p.Verifier.(type) {
+	case nil:
+		report, err := ParseRemoteReport(reportBytes)
+		return report, nil, err
+	case advisoryReportVerifier:
+		return verifier.VerifyRemoteReportWithAdvisories(reportBytes)
+	default:
+		report, err := verifier.VerifyRemoteReport(reportBytes)
+		return report, nil, err
+	}
+}
+
+// tcbAction returns how the policy handles the TCB status.
+// If the status is in both lists, it is accepted silently.
+func (p TrustPolicy) tcbAction(status tcbstatus.Status) TCBAction {
+	if containsTCBStatus(p.TCBStatuses, status) {
+		return TCBActionAccept
+	}
+	if containsTCBStatus(p.WarnTCBStatuses, status) {
+		return TCBActionWarn
+	}
+	return TCBActionReject
+}
+
+// ParseTCBStatuses parses the names of TCB statuses (e.g. UpToDate, SWHardeningNeeded).
+func ParseTCBStatuses(names []string) ([]tcbstatus.Status, error) {
+	statuses := make([]tcbstatus.Status, 0, len(names))
+	for _, name := range names {
+		status, err := parseTCBStatus(name)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func parseTCBStatus(name string) (tcbstatus.Status, error) {
+	for status := tcbstatus.UpToDate; status <= tcbstatus.Unknown; status++ {
+		if status.String() == name {
+			return status, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid TCB status: %s", name)
+}
+
+func containsTCBStatus(statuses []tcbstatus.Status, status tcbstatus.Status) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func containsID(ids [][]byte, id []byte) bool {
+	for _, i := range ids {
+		if bytes.Equal(i, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeHexIDs(hexIDs []string) ([][]byte, error) {
+	ids := make([][]byte, 0, len(hexIDs))
+	for _, hexID := range hexIDs {
+		id, err := hex.DecodeString(hexID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex ID(%s): %w", hexID, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
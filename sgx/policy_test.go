@@ -0,0 +1,129 @@
+package sgx_test
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+
+	"github.com/edgelesssys/ego/attestation/tcbstatus"
+	"github.com/medibloc/panacea-doracle/config"
+	"github.com/medibloc/panacea-doracle/sgx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustPolicyVerify(t *testing.T) {
+	useEnclave(t, newSimulatedEnclave(t, filepath.Join(t.TempDir(), "seal_key")))
+
+	self, err := sgx.GetSelfEnclaveInfo()
+	require.NoError(t, err)
+
+	data := []byte("hello")
+	report, err := sgx.GenerateRemoteReport(data)
+	require.NoError(t, err)
+
+	conf := config.DefaultConfig().Enclave
+
+	policy, err := sgx.NewTrustPolicy(conf, self)
+	require.NoError(t, err)
+
+	// no unique ID is trusted yet
+	_, err = policy.Verify(report, data)
+	require.ErrorContains(t, err, "invalid unique ID")
+
+	parsed, err := policy.WithDefaultUniqueIDs(self.UniqueID).Verify(report, data)
+	require.NoError(t, err)
+	require.Equal(t, self.UniqueID, parsed.Report.UniqueID)
+	require.Equal(t, sgx.TCBActionAccept, parsed.TCBAction)
+
+	_, err = policy.WithDefaultUniqueIDs(self.UniqueID).Verify(report, []byte("wrong"))
+	require.ErrorContains(t, err, "invalid data")
+
+	// the unique IDs in the config take precedence over the default ones
+	conf.TrustedUniqueIDs = []string{hex.EncodeToString([]byte("another-unique-id"))}
+	policy, err = sgx.NewTrustPolicy(conf, self)
+	require.NoError(t, err)
+	_, err = policy.WithDefaultUniqueIDs(self.UniqueID).Verify(report, data)
+	require.ErrorContains(t, err, "invalid unique ID")
+
+	conf.TrustedUniqueIDs = append(conf.TrustedUniqueIDs, self.UniqueIDHex())
+	policy, err = sgx.NewTrustPolicy(conf, self)
+	require.NoError(t, err)
+	_, err = policy.Verify(report, data)
+	require.NoError(t, err)
+}
+
+func TestTrustPolicyVerifyAllowlists(t *testing.T) {
+	useEnclave(t, newSimulatedEnclave(t, filepath.Join(t.TempDir(), "seal_key")))
+
+	self, err := sgx.GetSelfEnclaveInfo()
+	require.NoError(t, err)
+
+	data := []byte("hello")
+	report, err := sgx.GenerateRemoteReport(data)
+	require.NoError(t, err)
+
+	policy := sgx.NewExactTrustPolicy(*self)
+	_, err = policy.Verify(report, data)
+	require.NoError(t, err)
+
+	p := *policy
+	p.SignerIDs = [][]byte{[]byte("another-signer-id")}
+	_, err = p.Verify(report, data)
+	require.ErrorContains(t, err, "invalid signer ID")
+
+	p = *policy
+	p.ProductIDs = [][]byte{{2}, {3}}
+	_, err = p.Verify(report, data)
+	require.ErrorContains(t, err, "invalid product ID")
+
+	p.ProductIDs = append(p.ProductIDs, self.ProductID)
+	_, err = p.Verify(report, data)
+	require.NoError(t, err)
+
+	p = *policy
+	p.MinSecurityVersion = sgx.PromisedMinSecurityVersion + 1
+	_, err = p.Verify(report, data)
+	require.ErrorContains(t, err, "invalid security version")
+
+	p = *policy
+	p.TCBStatuses = []tcbstatus.Status{tcbstatus.SWHardeningNeeded}
+	result, err := p.Verify(report, data)
+	require.ErrorContains(t, err, "TCB status is not allowed")
+	require.Equal(t, sgx.TCBActionReject, result.TCBAction)
+}
+
+func TestTrustPolicyVerifyTCBWarning(t *testing.T) {
+	useEnclave(t, newSimulatedEnclave(t, filepath.Join(t.TempDir(), "seal_key")))
+
+	self, err := sgx.GetSelfEnclaveInfo()
+	require.NoError(t, err)
+
+	data := []byte("hello")
+	report, err := sgx.GenerateRemoteReport(data)
+	require.NoError(t, err)
+
+	conf := config.DefaultConfig().Enclave
+	conf.AllowedTCBStatuses = []string{"SWHardeningNeeded"}
+	conf.WarnTCBStatuses = []string{"UpToDate"}
+	policy, err := sgx.NewTrustPolicy(conf, self)
+	require.NoError(t, err)
+
+	result, err := policy.WithDefaultUniqueIDs(self.UniqueID).Verify(report, data)
+	require.NoError(t, err)
+	require.Equal(t, sgx.TCBActionWarn, result.TCBAction)
+	require.Len(t, result.Warnings, 1)
+	require.Equal(t, "UpToDate", result.LogFields()["tcb_status"])
+
+	conf.WarnTCBStatuses = []string{"Trusted"}
+	_, err = sgx.NewTrustPolicy(conf, self)
+	require.Error(t, err)
+}
+
+func TestParseTCBStatuses(t *testing.T) {
+	statuses, err := sgx.ParseTCBStatuses([]string{"UpToDate", "SWHardeningNeeded"})
+	require.NoError(t, err)
+	require.Equal(t, []tcbstatus.Status{tcbstatus.UpToDate, tcbstatus.SWHardeningNeeded}, statuses)
+
+	_, err = sgx.ParseTCBStatuses([]string{"Trusted"})
+	require.Error(t, err)
+}
@@ -1,57 +1,335 @@
 package sgx
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
+	"io"
 	"os"
+	"sync"
+	"time"
 
-	"github.com/edgelesssys/ego/ecrypto"
+	"github.com/medibloc/panacea-doracle/config"
+	"github.com/medibloc/panacea-doracle/crypto"
 	log "github.com/sirupsen/logrus"
 )
 
-// SealToFile seals the data with unique ID and stores it to file.
-func SealToFile(data []byte, filePath string) error {
-	sealedData, err := ecrypto.SealWithUniqueKey(data, nil)
+// sealedFileMagic is the prefix of sealed files.
+// The files without this prefix are regarded as sealed with the unique key, before the header was introduced.
+var sealedFileMagic = []byte("DSF")
+
+const (
+	// sealedFileVersion is the version of the header written by SealToFile.
+	sealedFileVersion = 1
+
+	sealPolicyCodeUnique  = 1
+	sealPolicyCodeProduct = 2
+)
+
+// SealedFileType is the type of data in a sealed file.
+type SealedFileType byte
+
+const (
+	// SealedFileTypeUnknown is the type of files sealed before the type was recorded.
+	SealedFileTypeUnknown SealedFileType = iota
+	SealedFileTypeOracleKey
+	SealedFileTypeNodeKey
+	SealedFileTypeLightClientSnapshot
+)
+
+func (t SealedFileType) String() string {
+	switch t {
+	case SealedFileTypeOracleKey:
+		return "oracle key"
+	case SealedFileTypeNodeKey:
+		return "node key"
+	case SealedFileTypeLightClientSnapshot:
+		return "light client snapshot"
+	default:
+		return "unknown"
+	}
+}
+
+// isPrivKey returns true if the data of the type is a secp256k1 private key.
+func (t SealedFileType) isPrivKey() bool {
+	return t == SealedFileTypeOracleKey || t == SealedFileTypeNodeKey
+}
+
+// SealedFileHeader is the metadata of a sealed file, which can be read without unsealing.
+//
+// The header of version 1 is:
+// magic(3) | version(1) | seal policy(1) | type(1) | created at(8, unix seconds, big endian) |
+// unique ID length(1) | unique ID | public key checksum length(1) | public key checksum
+type SealedFileHeader struct {
+	// Version is 0 if the file has no header.
+	Version    byte
+	SealPolicy string
+	Type       SealedFileType
+	CreatedAt  time.Time
+	// UniqueID is the unique ID of the enclave which sealed the file.
+	UniqueID []byte
+	// PubKeyChecksum is the SHA256 hash of the compressed public key, if the file contains a private key.
+	PubKeyChecksum []byte
+}
+
+var (
+	currentSealPolicy      = config.SealPolicyUnique
+	currentSealPolicyMutex sync.RWMutex
+)
+
+// SetSealPolicy sets the seal policy used by SealToFile and Seal.
+func SetSealPolicy(policy string) error {
+	if _, err := sealPolicyCode(policy); err != nil {
+		return err
+	}
+
+	currentSealPolicyMutex.Lock()
+	defer currentSealPolicyMutex.Unlock()
+
+	currentSealPolicy = policy
+	return nil
+}
+
+// CurrentSealPolicy returns the seal policy used by SealToFile and Seal.
+// If SetSealPolicy is not called, the unique key is used.
+func CurrentSealPolicy() string {
+	currentSealPolicyMutex.RLock()
+	defer currentSealPolicyMutex.RUnlock()
+
+	return currentSealPolicy
+}
+
+func sealPolicyCode(policy string) (byte, error) {
+	switch policy {
+	case config.SealPolicyUnique:
+		return sealPolicyCodeUnique, nil
+	case config.SealPolicyProduct:
+		return sealPolicyCodeProduct, nil
+	default:
+		return 0, fmt.Errorf("invalid seal policy: %s", policy)
+	}
+}
+
+func sealPolicyFromCode(code byte) (string, error) {
+	switch code {
+	case sealPolicyCodeUnique:
+		return config.SealPolicyUnique, nil
+	case sealPolicyCodeProduct:
+		return config.SealPolicyProduct, nil
+	default:
+		return "", fmt.Errorf("invalid seal policy code: %d", code)
+	}
+}
+
+// SealWithPolicy seals the data with the key of the seal policy.
+func SealWithPolicy(data []byte, policy string) ([]byte, error) {
+	switch policy {
+	case config.SealPolicyUnique:
+		return CurrentEnclave().SealWithUniqueKey(data)
+	case config.SealPolicyProduct:
+		return CurrentEnclave().SealWithProductKey(data)
+	default:
+		return nil, fmt.Errorf("invalid seal policy: %s", policy)
+	}
+}
+
+// SealToFile seals the data with the current seal policy and stores it to file.
+func SealToFile(data []byte, filePath string, fileType SealedFileType) error {
+	return SealToFileWithPolicy(data, filePath, fileType, CurrentSealPolicy())
+}
+
+// SealToFileWithPolicy seals the data with the seal policy and stores it to file with the header.
+// The file is written atomically with permission 0600.
+func SealToFileWithPolicy(data []byte, filePath string, fileType SealedFileType, policy string) error {
+	policyCode, err := sealPolicyCode(policy)
+	if err != nil {
+		return err
+	}
+
+	selfReport, err := CurrentEnclave().GetSelfReport()
+	if err != nil {
+		return fmt.Errorf("failed to get self report: %w", err)
+	}
+
+	header := SealedFileHeader{
+		Version:    sealedFileVersion,
+		SealPolicy: policy,
+		Type:       fileType,
+		CreatedAt:  time.Now(),
+		UniqueID:   selfReport.UniqueID,
+	}
+	if fileType.isPrivKey() {
+		header.PubKeyChecksum = pubKeyChecksum(data)
+	}
+
+	sealedData, err := SealWithPolicy(data, policy)
 	if err != nil {
-		return fmt.Errorf("failed to seal oracle private key: %w", err)
+		return fmt.Errorf("failed to seal %s: %w", fileType, err)
 	}
 
-	if err := os.WriteFile(filePath, sealedData, 0644); err != nil {
-		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	fileData := append(header.marshal(policyCode), sealedData...)
+	if err := WriteFileAtomic(filePath, fileData, 0600); err != nil {
+		return err
 	}
-	log.Infof("%s is sealed and written successfully", filePath)
+	log.Infof("%s is sealed with the %s key and written successfully", filePath, policy)
 
 	return nil
 }
 
-func UnsealFromFile(filePath string) ([]byte, error) {
-	sealed, err := os.ReadFile(filePath)
+// UnsealFromFile unseals the data in the sealed file.
+// It returns an error if the file is not of the type, or if it was sealed with the unique key of another enclave.
+func UnsealFromFile(filePath string, fileType SealedFileType) ([]byte, error) {
+	fileData, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
 	}
 
-	key, err := ecrypto.Unseal(sealed, nil)
+	header, sealed, err := parseSealedFile(fileData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unseal oracle key: %w", err)
+		return nil, fmt.Errorf("invalid sealed file %s: %w", filePath, err)
 	}
 
-	return key, nil
+	if header.Type != SealedFileTypeUnknown && header.Type != fileType {
+		return nil, fmt.Errorf("%s contains the %s, not the %s", filePath, header.Type, fileType)
+	}
+
+	if header.SealPolicy == config.SealPolicyUnique && len(header.UniqueID) > 0 {
+		selfReport, err := CurrentEnclave().GetSelfReport()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get self report: %w", err)
+		}
+		if !bytes.Equal(header.UniqueID, selfReport.UniqueID) {
+			return nil, fmt.Errorf("%s was sealed with the unique key of another enclave. uniqueID(%X), current uniqueID(%X). "+
+				"re-seal it with the product key by 'migrate-sealed' of the enclave before upgrading",
+				filePath, header.UniqueID, selfReport.UniqueID)
+		}
+	}
+
+	data, err := CurrentEnclave().Unseal(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unseal %s: %w", fileType, err)
+	}
+
+	if len(header.PubKeyChecksum) > 0 && !bytes.Equal(header.PubKeyChecksum, pubKeyChecksum(data)) {
+		return nil, fmt.Errorf("the public key of the %s in %s doesn't match the checksum in the header", fileType, filePath)
+	}
+
+	return data, nil
+}
+
+// ReadSealedFileHeader returns the header of the sealed file without unsealing it.
+func ReadSealedFileHeader(filePath string) (*SealedFileHeader, error) {
+	fileData, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+
+	header, _, err := parseSealedFile(fileData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sealed file %s: %w", filePath, err)
+	}
+	return header, nil
+}
+
+// MigrateSealedFile re-seals the sealed file with the seal policy, and returns the previous seal policy of the file.
+// During an upgrade, the old binary can migrate files to the product key, so that the new binary can unseal them.
+// Then, the new binary can migrate them back to its unique key.
+func MigrateSealedFile(filePath string, fileType SealedFileType, policy string) (string, error) {
+	header, err := ReadSealedFileHeader(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := UnsealFromFile(filePath, fileType)
+	if err != nil {
+		return "", err
+	}
+
+	if err := SealToFileWithPolicy(data, filePath, fileType, policy); err != nil {
+		return "", err
+	}
+	return header.SealPolicy, nil
+}
+
+func (h SealedFileHeader) marshal(policyCode byte) []byte {
+	bz := make([]byte, 0, len(sealedFileMagic)+13+len(h.UniqueID)+len(h.PubKeyChecksum))
+	bz = append(bz, sealedFileMagic...)
+	bz = append(bz, h.Version, policyCode, byte(h.Type))
+	bz = binary.BigEndian.AppendUint64(bz, uint64(h.CreatedAt.Unix()))
+	bz = append(bz, byte(len(h.UniqueID)))
+	bz = append(bz, h.UniqueID...)
+	bz = append(bz, byte(len(h.PubKeyChecksum)))
+	bz = append(bz, h.PubKeyChecksum...)
+	return bz
+}
+
+// parseSealedFile returns the header and the sealed data in the file.
+func parseSealedFile(fileData []byte) (*SealedFileHeader, []byte, error) {
+	if !bytes.HasPrefix(fileData, sealedFileMagic) {
+		return &SealedFileHeader{SealPolicy: config.SealPolicyUnique}, fileData, nil
+	}
+
+	r := bytes.NewReader(fileData[len(sealedFileMagic):])
+	readByte := func() byte {
+		b, _ := r.ReadByte()
+		return b
+	}
+	readBytes := func(n int) []byte {
+		bz := make([]byte, n)
+		if _, err := io.ReadFull(r, bz); err != nil {
+			return nil
+		}
+		return bz
+	}
+
+	header := &SealedFileHeader{Version: readByte()}
+	if header.Version != sealedFileVersion {
+		return nil, nil, fmt.Errorf("unsupported version: %d", header.Version)
+	}
+
+	policy, err := sealPolicyFromCode(readByte())
+	if err != nil {
+		return nil, nil, err
+	}
+	header.SealPolicy = policy
+
+	header.Type = SealedFileType(readByte())
+	createdAt := readBytes(8)
+	if createdAt == nil {
+		return nil, nil, fmt.Errorf("the header is too short")
+	}
+	header.CreatedAt = time.Unix(int64(binary.BigEndian.Uint64(createdAt)), 0)
+	header.UniqueID = readBytes(int(readByte()))
+	header.PubKeyChecksum = readBytes(int(readByte()))
+	if header.UniqueID == nil || header.PubKeyChecksum == nil {
+		return nil, nil, fmt.Errorf("the header is too short")
+	}
+
+	return header, fileData[len(fileData)-r.Len():], nil
+}
+
+func pubKeyChecksum(privKey []byte) []byte {
+	_, pubKey := crypto.PrivKeyFromBytes(privKey)
+	checksum := sha256.Sum256(pubKey.SerializeCompressed())
+	return checksum[:]
 }
 
-// Seal returns data sealed with unique ID in SGX-enabled environments
+// Seal returns data sealed with the current seal policy in SGX-enabled environments
 // If SGX is disabled, it returns the data as is.
 func Seal(data []byte, enclaveEnabled bool) ([]byte, error) {
 	if enclaveEnabled {
-		return ecrypto.SealWithUniqueKey(data, nil)
+		return SealWithPolicy(data, CurrentSealPolicy())
 	} else {
 		return data, nil
 	}
 }
 
-// Unseal returns data unsealed with unique ID in SGX-enabled environments
+// Unseal returns data unsealed with any key of the enclave in SGX-enabled environments
 // If SGX is disabled, it returns the data as is.
 func Unseal(data []byte, enclaveEnabled bool) ([]byte, error) {
 	if enclaveEnabled {
-		return ecrypto.Unseal(data, nil)
+		return CurrentEnclave().Unseal(data)
 	} else {
 		return data, nil
 	}
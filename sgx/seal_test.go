@@ -1,28 +1,147 @@
 package sgx_test
 
 import (
-	"github.com/medibloc/panacea-doracle/sgx"
-	"github.com/stretchr/testify/require"
+	"crypto/sha256"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/medibloc/panacea-doracle/config"
+	"github.com/medibloc/panacea-doracle/crypto"
+	"github.com/medibloc/panacea-doracle/sgx"
+	"github.com/stretchr/testify/require"
 )
 
-func TestSealAndUnsealFile(t *testing.T) {
-	data := []byte("hello world")
+// newUpgradedSimulatedEnclave returns a simulated enclave which imitates a new version of the enclave by newSimulatedEnclave.
+// It has the same signer ID and product ID, but a different unique ID.
+func newUpgradedSimulatedEnclave(t *testing.T, sealKeyPath string) *sgx.SimulatedEnclave {
+	info := sgx.NewEnclaveInfo([]byte{1}, []byte("signer-id"), []byte("new-unique-id"))
+	e, err := sgx.NewSimulatedEnclave(sealKeyPath, *info)
+	require.NoError(t, err)
+	return e
+}
+
+func TestSealToFileWithPolicy(t *testing.T) {
+	sealKeyPath := filepath.Join(t.TempDir(), "seal_key")
+	useEnclave(t, newSimulatedEnclave(t, sealKeyPath))
+
+	uniquePath := filepath.Join(t.TempDir(), "unique.sealed")
+	productPath := filepath.Join(t.TempDir(), "product.sealed")
+	require.NoError(t, sgx.SealToFileWithPolicy([]byte("hello"), uniquePath, sgx.SealedFileTypeLightClientSnapshot, config.SealPolicyUnique))
+	require.NoError(t, sgx.SealToFileWithPolicy([]byte("hello"), productPath, sgx.SealedFileTypeLightClientSnapshot, config.SealPolicyProduct))
+
+	header, err := sgx.ReadSealedFileHeader(productPath)
+	require.NoError(t, err)
+	require.Equal(t, config.SealPolicyProduct, header.SealPolicy)
+
+	// only the file sealed with the product key can be unsealed after the upgrade
+	useEnclave(t, newUpgradedSimulatedEnclave(t, sealKeyPath))
+
+	_, err = sgx.UnsealFromFile(uniquePath, sgx.SealedFileTypeLightClientSnapshot)
+	require.ErrorContains(t, err, "another enclave")
+
+	data, err := sgx.UnsealFromFile(productPath, sgx.SealedFileTypeLightClientSnapshot)
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), data)
+
+	require.Error(t, sgx.SealToFileWithPolicy([]byte("hello"), productPath, sgx.SealedFileTypeLightClientSnapshot, "signer"))
+}
+
+func TestUnsealFromFileWithoutHeader(t *testing.T) {
+	e := newSimulatedEnclave(t, filepath.Join(t.TempDir(), "seal_key"))
+	useEnclave(t, e)
+
+	// files were sealed with the unique key without the header, before the header was introduced
+	sealed, err := e.SealWithUniqueKey([]byte("hello"))
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "legacy.sealed")
+	require.NoError(t, os.WriteFile(path, sealed, 0600))
+
+	header, err := sgx.ReadSealedFileHeader(path)
+	require.NoError(t, err)
+	require.Equal(t, byte(0), header.Version)
+	require.Equal(t, config.SealPolicyUnique, header.SealPolicy)
+	require.Equal(t, sgx.SealedFileTypeUnknown, header.Type)
+
+	data, err := sgx.UnsealFromFile(path, sgx.SealedFileTypeOracleKey)
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), data)
+}
+
+func TestMigrateSealedFile(t *testing.T) {
+	sealKeyPath := filepath.Join(t.TempDir(), "seal_key")
+	useEnclave(t, newSimulatedEnclave(t, sealKeyPath))
+
+	privKey, err := crypto.NewPrivKey()
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "oracle_priv_key.sealed")
+	require.NoError(t, sgx.SealToFileWithPolicy(privKey.Serialize(), path, sgx.SealedFileTypeOracleKey, config.SealPolicyUnique))
 
-	testDir := "/test"
-	err := os.MkdirAll(testDir, os.ModePerm)
+	// the old binary migrates the file to the product key before the upgrade
+	prevPolicy, err := sgx.MigrateSealedFile(path, sgx.SealedFileTypeOracleKey, config.SealPolicyProduct)
 	require.NoError(t, err)
-	defer os.RemoveAll(testDir)
+	require.Equal(t, config.SealPolicyUnique, prevPolicy)
 
-	dataPath := filepath.Join(testDir, "temp.sealed")
+	// the new binary migrates the file back to its unique key after the upgrade
+	useEnclave(t, newUpgradedSimulatedEnclave(t, sealKeyPath))
+	prevPolicy, err = sgx.MigrateSealedFile(path, sgx.SealedFileTypeOracleKey, config.SealPolicyUnique)
+	require.NoError(t, err)
+	require.Equal(t, config.SealPolicyProduct, prevPolicy)
 
-	err = sgx.SealToFile(data, dataPath)
+	data, err := sgx.UnsealFromFile(path, sgx.SealedFileTypeOracleKey)
 	require.NoError(t, err)
+	require.Equal(t, privKey.Serialize(), data)
+
+	_, err = sgx.MigrateSealedFile(filepath.Join(t.TempDir(), "not-exist"), sgx.SealedFileTypeOracleKey, config.SealPolicyUnique)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
 
-	dataRetrieved, err := sgx.UnsealFromFile(dataPath)
+func TestSealedFileHeader(t *testing.T) {
+	e := newSimulatedEnclave(t, filepath.Join(t.TempDir(), "seal_key"))
+	useEnclave(t, e)
+
+	privKey, err := crypto.NewPrivKey()
 	require.NoError(t, err)
 
-	require.Equal(t, dataRetrieved, data)
+	path := filepath.Join(t.TempDir(), "node_priv_key.sealed")
+	require.NoError(t, sgx.SealToFile(privKey.Serialize(), path, sgx.SealedFileTypeNodeKey))
+
+	fileInfo, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), fileInfo.Mode().Perm())
+
+	selfReport, err := e.GetSelfReport()
+	require.NoError(t, err)
+
+	header, err := sgx.ReadSealedFileHeader(path)
+	require.NoError(t, err)
+	require.Equal(t, byte(1), header.Version)
+	require.Equal(t, config.SealPolicyUnique, header.SealPolicy)
+	require.Equal(t, sgx.SealedFileTypeNodeKey, header.Type)
+	require.WithinDuration(t, time.Now(), header.CreatedAt, time.Minute)
+	require.Equal(t, selfReport.UniqueID, header.UniqueID)
+	pubKeyChecksum := sha256.Sum256(privKey.PubKey().SerializeCompressed())
+	require.Equal(t, pubKeyChecksum[:], header.PubKeyChecksum)
+
+	_, err = sgx.UnsealFromFile(path, sgx.SealedFileTypeOracleKey)
+	require.ErrorContains(t, err, "contains the node key, not the oracle key")
+
+	data, err := sgx.UnsealFromFile(path, sgx.SealedFileTypeNodeKey)
+	require.NoError(t, err)
+	require.Equal(t, privKey.Serialize(), data)
+}
+
+func TestUnsealFromFileInvalidHeader(t *testing.T) {
+	useEnclave(t, newSimulatedEnclave(t, filepath.Join(t.TempDir(), "seal_key")))
+
+	path := filepath.Join(t.TempDir(), "oracle_priv_key.sealed")
+	require.NoError(t, os.WriteFile(path, []byte{'D', 'S', 'F', 9, 1}, 0600))
+	_, err := sgx.UnsealFromFile(path, sgx.SealedFileTypeOracleKey)
+	require.ErrorContains(t, err, "unsupported version")
+
+	require.NoError(t, os.WriteFile(path, []byte{'D', 'S', 'F', 1, 1, 1, 0, 0}, 0600))
+	_, err = sgx.UnsealFromFile(path, sgx.SealedFileTypeOracleKey)
+	require.ErrorContains(t, err, "too short")
 }
@@ -0,0 +1,122 @@
+package sgx
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+const (
+	// oeInfoSectionName is the ELF section where ego (Open Enclave) stores the enclave properties including the SIGSTRUCT.
+	oeInfoSectionName = ".oeinfo"
+
+	sigStructSize           = 1808
+	sigStructModulusSize    = 384
+	sigStructAttributeDebug = 0x02
+)
+
+// sigStructHeader is the fixed header at the beginning of a SIGSTRUCT.
+var sigStructHeader = []byte{0x06, 0x00, 0x00, 0x00, 0xe1, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+// SignedEnclaveInfo is the identity of an enclave, read from the SIGSTRUCT in a signed binary.
+type SignedEnclaveInfo struct {
+	EnclaveInfo
+	SecurityVersion uint
+	Debug           bool
+	// SignerPubKey is the RSA public key which signed the SIGSTRUCT.
+	SignerPubKey *rsa.PublicKey
+}
+
+// ReadSignedEnclaveInfo reads the identity of the enclave from a binary signed by 'ego sign', without running it.
+// The unique ID (MRENCLAVE) is the ENCLAVEHASH recorded in the SIGSTRUCT by the signer. It is not measured from the
+// enclave image in the binary, so it is only as trustworthy as the signer.
+// The signer ID (MRSIGNER) is derived from the public key which signed the SIGSTRUCT.
+func ReadSignedEnclaveInfo(binaryPath string) (*SignedEnclaveInfo, error) {
+	f, err := elf.Open(binaryPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ELF binary %s: %w", binaryPath, err)
+	}
+	defer f.Close()
+
+	section := f.Section(oeInfoSectionName)
+	if section == nil {
+		return nil, fmt.Errorf("%s section is not found. %s is not an ego binary", oeInfoSectionName, binaryPath)
+	}
+	data, err := section.Data()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s section: %w", oeInfoSectionName, err)
+	}
+
+	offset := bytes.Index(data, sigStructHeader)
+	if offset < 0 || offset+sigStructSize > len(data) {
+		return nil, fmt.Errorf("SIGSTRUCT is not found. %s is not signed", binaryPath)
+	}
+
+	return parseSigStruct(data[offset : offset+sigStructSize])
+}
+
+// parseSigStruct parses the SIGSTRUCT and verifies its signature.
+func parseSigStruct(sigStruct []byte) (*SignedEnclaveInfo, error) {
+	modulus := sigStruct[128:512]
+	exponent := binary.LittleEndian.Uint32(sigStruct[512:516])
+	signature := sigStruct[516:900]
+	attributes := sigStruct[928:944]
+	enclaveHash := sigStruct[960:992]
+	isvProdID := binary.LittleEndian.Uint16(sigStruct[1024:1026])
+	isvSVN := binary.LittleEndian.Uint16(sigStruct[1026:1028])
+
+	pubKey := &rsa.PublicKey{
+		N: new(big.Int).SetBytes(reverseBytes(modulus)),
+		E: int(exponent),
+	}
+
+	// the header and the body of the SIGSTRUCT are signed with RSASSA-PKCS1-v1_5
+	signedData := append(append([]byte{}, sigStruct[0:128]...), sigStruct[900:1028]...)
+	hash := sha256.Sum256(signedData)
+	if err := rsa.VerifyPKCS1v15(pubKey, crypto.SHA256, hash[:], reverseBytes(signature)); err != nil {
+		return nil, fmt.Errorf("invalid signature of SIGSTRUCT: %w", err)
+	}
+
+	signerID, err := SignerIDFromPubKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// the product ID in reports is 16 bytes, where the ISVPRODID is set in little-endian
+	productID := make([]byte, 16)
+	binary.LittleEndian.PutUint16(productID, isvProdID)
+
+	return &SignedEnclaveInfo{
+		EnclaveInfo:     *NewEnclaveInfo(productID, signerID, append([]byte{}, enclaveHash...)),
+		SecurityVersion: uint(isvSVN),
+		Debug:           attributes[0]&sigStructAttributeDebug != 0,
+		SignerPubKey:    pubKey,
+	}, nil
+}
+
+// SignerIDFromPubKey returns the signer ID (MRSIGNER) of enclaves signed by the RSA key,
+// which is the SHA256 hash of the little-endian modulus.
+func SignerIDFromPubKey(pubKey *rsa.PublicKey) ([]byte, error) {
+	if pubKey.N.BitLen() > sigStructModulusSize*8 {
+		return nil, errors.New("the signer key must be a 3072-bit RSA key")
+	}
+	modulus := make([]byte, sigStructModulusSize)
+	pubKey.N.FillBytes(modulus)
+	hash := sha256.Sum256(reverseBytes(modulus))
+	return hash[:], nil
+}
+
+// reverseBytes returns a reversed copy, to convert between the little-endian of SGX and the big-endian of math/big.
+func reverseBytes(b []byte) []byte {
+	reversed := make([]byte, len(b))
+	for i := range b {
+		reversed[len(b)-1-i] = b[i]
+	}
+	return reversed
+}
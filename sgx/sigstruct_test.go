@@ -0,0 +1,135 @@
+package sgx_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/medibloc/panacea-doracle/sgx"
+	"github.com/stretchr/testify/require"
+)
+
+// newSigStruct returns a SIGSTRUCT signed by the key, as 'ego sign' generates.
+func newSigStruct(t *testing.T, key *rsa.PrivateKey, mrEnclave []byte, productID, securityVersion uint16, debug bool) []byte {
+	sigStruct := make([]byte, 1808)
+	copy(sigStruct, []byte{0x06, 0x00, 0x00, 0x00, 0xe1, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00})
+	copy(sigStruct[24:], []byte{0x01, 0x01, 0x00, 0x00, 0x60, 0x00, 0x00, 0x00, 0x60, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00})
+	copy(sigStruct[128:512], reverse(key.N.FillBytes(make([]byte, 384))))
+	binary.LittleEndian.PutUint32(sigStruct[512:516], uint32(key.E))
+	if debug {
+		sigStruct[928] = 0x02
+	}
+	copy(sigStruct[960:992], mrEnclave)
+	binary.LittleEndian.PutUint16(sigStruct[1024:1026], productID)
+	binary.LittleEndian.PutUint16(sigStruct[1026:1028], securityVersion)
+
+	hash := sha256.Sum256(append(append([]byte{}, sigStruct[0:128]...), sigStruct[900:1028]...))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	require.NoError(t, err)
+	copy(sigStruct[516:900], reverse(signature))
+
+	return sigStruct
+}
+
+// writeELF writes a minimal ELF64 file which has only the .oeinfo section.
+func writeELF(t *testing.T, path string, oeInfo []byte) {
+	shstrtab := []byte("\x00.oeinfo\x00.shstrtab\x00")
+
+	le := binary.LittleEndian
+	oeInfoOffset := uint64(64)
+	shstrtabOffset := oeInfoOffset + uint64(len(oeInfo))
+	shOffset := shstrtabOffset + uint64(len(shstrtab))
+
+	header := make([]byte, 64)
+	copy(header, []byte{0x7f, 'E', 'L', 'F', 2, 1, 1})
+	le.PutUint16(header[16:], 2)  // executable
+	le.PutUint16(header[18:], 62) // x86-64
+	le.PutUint32(header[20:], 1)
+	le.PutUint64(header[40:], shOffset)
+	le.PutUint16(header[52:], 64)
+	le.PutUint16(header[54:], 56)
+	le.PutUint16(header[58:], 64)
+	le.PutUint16(header[60:], 3) // null, .oeinfo and .shstrtab
+	le.PutUint16(header[62:], 2)
+
+	sectionHeader := func(name, sectionType uint32, offset, size uint64) []byte {
+		sh := make([]byte, 64)
+		le.PutUint32(sh[0:], name)
+		le.PutUint32(sh[4:], sectionType)
+		le.PutUint64(sh[24:], offset)
+		le.PutUint64(sh[32:], size)
+		le.PutUint64(sh[48:], 1)
+		return sh
+	}
+
+	var file []byte
+	file = append(file, header...)
+	file = append(file, oeInfo...)
+	file = append(file, shstrtab...)
+	file = append(file, make([]byte, 64)...)
+	file = append(file, sectionHeader(1, 1, oeInfoOffset, uint64(len(oeInfo)))...)
+	file = append(file, sectionHeader(9, 3, shstrtabOffset, uint64(len(shstrtab)))...)
+
+	require.NoError(t, os.WriteFile(path, file, 0600))
+}
+
+func reverse(b []byte) []byte {
+	reversed := make([]byte, len(b))
+	for i := range b {
+		reversed[len(b)-1-i] = b[i]
+	}
+	return reversed
+}
+
+func TestReadSignedEnclaveInfo(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 3072)
+	require.NoError(t, err)
+
+	mrEnclave := sha256.Sum256([]byte("enclave"))
+	sigStruct := newSigStruct(t, key, mrEnclave[:], 1, 2, true)
+
+	// the SIGSTRUCT is located after other enclave properties in the .oeinfo section
+	path := filepath.Join(t.TempDir(), "doracled")
+	writeELF(t, path, append(make([]byte, 100), sigStruct...))
+
+	info, err := sgx.ReadSignedEnclaveInfo(path)
+	require.NoError(t, err)
+	require.Equal(t, mrEnclave[:], info.UniqueID)
+	require.Equal(t, append([]byte{1}, make([]byte, 15)...), info.ProductID)
+	require.Equal(t, uint(2), info.SecurityVersion)
+	require.True(t, info.Debug)
+	require.Equal(t, key.N, info.SignerPubKey.N)
+
+	signerID, err := sgx.SignerIDFromPubKey(&key.PublicKey)
+	require.NoError(t, err)
+	require.Equal(t, signerID, info.SignerID)
+}
+
+func TestReadSignedEnclaveInfoInvalid(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 3072)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "doracled")
+
+	// not signed
+	writeELF(t, path, make([]byte, 4096))
+	_, err = sgx.ReadSignedEnclaveInfo(path)
+	require.ErrorContains(t, err, "not signed")
+
+	// tampered
+	sigStruct := newSigStruct(t, key, make([]byte, 32), 1, 1, false)
+	sigStruct[960] ^= 0xff
+	writeELF(t, path, sigStruct)
+	_, err = sgx.ReadSignedEnclaveInfo(path)
+	require.ErrorContains(t, err, "invalid signature")
+
+	// not an ELF
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0600))
+	_, err = sgx.ReadSignedEnclaveInfo(path)
+	require.Error(t, err)
+}
@@ -3,7 +3,6 @@ package sgx
 import (
 	"encoding/hex"
 	"fmt"
-	"github.com/edgelesssys/ego/enclave"
 )
 
 const dummyData = "dummy-data"
@@ -30,7 +29,7 @@ func GetSelfEnclaveInfo() (*EnclaveInfo, error) {
 		return nil, fmt.Errorf("failed to generate self-report: %w", err)
 	}
 
-	report, err := enclave.VerifyRemoteReport(reportBz)
+	report, err := ParseRemoteReport(reportBz)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve self-report: %w", err)
 	}
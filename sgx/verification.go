@@ -0,0 +1,59 @@
+package sgx
+
+import (
+	"encoding/hex"
+
+	"github.com/edgelesssys/ego/attestation"
+	log "github.com/sirupsen/logrus"
+)
+
+// TCBAction is how a TrustPolicy handles the TCB status of a report.
+type TCBAction int
+
+const (
+	TCBActionReject TCBAction = iota
+	TCBActionWarn
+	TCBActionAccept
+)
+
+func (a TCBAction) String() string {
+	switch a {
+	case TCBActionReject:
+		return "reject"
+	case TCBActionWarn:
+		return "warn"
+	case TCBActionAccept:
+		return "accept"
+	default:
+		return "unknown"
+	}
+}
+
+// VerificationResult is the result of verifying a remote report by a TrustPolicy.
+type VerificationResult struct {
+	Report    attestation.Report
+	TCBAction TCBAction
+	// TCBAdvisoryIDs are the Intel security advisories (e.g. INTEL-SA-00334) affecting the TCB of the report.
+	// They are set only if the verifier provides them, like the DCAPVerifier. The ego enclave doesn't.
+	TCBAdvisoryIDs []string
+	// Warnings are the reasons why the report was accepted with a warning.
+	Warnings []string
+}
+
+// LogFields returns the fields to be recorded in logs, e.g. for auditing votes.
+func (r *VerificationResult) LogFields() log.Fields {
+	if r == nil {
+		return log.Fields{}
+	}
+	fields := log.Fields{
+		"unique_id":        hex.EncodeToString(r.Report.UniqueID),
+		"security_version": r.Report.SecurityVersion,
+		"tcb_status":       r.Report.TCBStatus.String(),
+		"tcb_action":       r.TCBAction.String(),
+		"tcb_advisory_ids": r.TCBAdvisoryIDs,
+	}
+	if len(r.Warnings) > 0 {
+		fields["warnings"] = r.Warnings
+	}
+	return fields
+}
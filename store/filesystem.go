@@ -0,0 +1,137 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hashFileSuffix is the suffix of the file which contains the hex-encoded SHA256 hash of the file with the same name.
+// It is a check against corruption only, because it is as writable as the file.
+const hashFileSuffix = ".sha256"
+
+var _ Storage = &FileSystemStorage{}
+
+// FileSystemStorage stores files in a local directory.
+type FileSystemStorage struct {
+	rootDir string
+}
+
+// NewFileSystemStorage creates a FileSystemStorage which stores files under the root directory.
+func NewFileSystemStorage(rootDir string) (*FileSystemStorage, error) {
+	if err := os.MkdirAll(rootDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create storage dir %s: %w", rootDir, err)
+	}
+	return &FileSystemStorage{rootDir: rootDir}, nil
+}
+
+func (s *FileSystemStorage) UploadFile(path, name string, data []byte) error {
+	_, err := s.Upload(context.Background(), path, name, bytes.NewReader(data))
+	return err
+}
+
+func (s *FileSystemStorage) DownloadFile(path, name string) ([]byte, error) {
+	return downloadAll(context.Background(), s, path, name)
+}
+
+// Upload writes the data to a temp file, and renames it to the file, so that the file is never partially written.
+// The hash of the data is stored in a file with the .sha256 suffix.
+func (s *FileSystemStorage) Upload(ctx context.Context, path, name string, r io.Reader) ([]byte, error) {
+	filePath, err := s.filePath(path, name)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create dir %s: %w", dir, err)
+	}
+
+	tmpFile, err := os.CreateTemp(dir, name+".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmpFile, h), &contextReader{ctx: ctx, r: r})
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	hash := h.Sum(nil)
+
+	// the hash file is written first. if the data is not renamed, the mismatch is detected at the download.
+	if err := os.WriteFile(filePath+hashFileSuffix, []byte(hex.EncodeToString(hash)), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write hash of %s: %w", filePath, err)
+	}
+	if err := os.Rename(tmpFile.Name(), filePath); err != nil {
+		return nil, fmt.Errorf("failed to rename temp file to %s: %w", filePath, err)
+	}
+
+	return hash, nil
+}
+
+func (s *FileSystemStorage) Download(_ context.Context, path, name string) (io.ReadCloser, error) {
+	filePath, err := s.filePath(path, name)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, filePath)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filePath, err)
+	}
+
+	var expectedHash []byte
+	hashHex, err := os.ReadFile(filePath + hashFileSuffix)
+	if err == nil {
+		expectedHash, err = hex.DecodeString(strings.TrimSpace(string(hashHex)))
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("invalid hash of %s: %w", filePath, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to read hash of %s: %w", filePath, err)
+	}
+
+	return newHashVerifyingReader(f, expectedHash), nil
+}
+
+func (s *FileSystemStorage) filePath(path, name string) (string, error) {
+	if strings.HasSuffix(name, hashFileSuffix) {
+		return "", fmt.Errorf("invalid file name: %q", name)
+	}
+	key, err := objectKey(path, name)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.rootDir, filepath.FromSlash(key)), nil
+}
+
+// contextReader stops reading if the context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
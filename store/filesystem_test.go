@@ -0,0 +1,69 @@
+package store_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/medibloc/panacea-doracle/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSystemStorage(t *testing.T) {
+	rootDir := t.TempDir()
+	s, err := store.NewFileSystemStorage(rootDir)
+	require.NoError(t, err)
+
+	data := []byte("hello")
+	hash, err := s.Upload(context.Background(), "deals/1", "data", bytes.NewReader(data))
+	require.NoError(t, err)
+	expectedHash := sha256.Sum256(data)
+	require.Equal(t, expectedHash[:], hash)
+
+	downloaded, err := s.DownloadFile("deals/1", "data")
+	require.NoError(t, err)
+	require.Equal(t, data, downloaded)
+
+	// the file is overwritten
+	require.NoError(t, s.UploadFile("deals/1", "data", []byte("world")))
+	downloaded, err = s.DownloadFile("deals/1", "data")
+	require.NoError(t, err)
+	require.Equal(t, []byte("world"), downloaded)
+
+	_, err = s.DownloadFile("deals/1", "not-exist")
+	require.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func TestFileSystemStorageHashMismatch(t *testing.T) {
+	rootDir := t.TempDir()
+	s, err := store.NewFileSystemStorage(rootDir)
+	require.NoError(t, err)
+
+	require.NoError(t, s.UploadFile("deals/1", "data", []byte("hello")))
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "deals", "1", "data"), []byte("tampered"), 0600))
+
+	r, err := s.Download(context.Background(), "deals/1", "data")
+	require.NoError(t, err)
+	defer r.Close()
+	_, err = io.ReadAll(r)
+	require.ErrorIs(t, err, store.ErrHashMismatch)
+}
+
+func TestFileSystemStorageInvalidPath(t *testing.T) {
+	s, err := store.NewFileSystemStorage(t.TempDir())
+	require.NoError(t, err)
+
+	for _, c := range []struct{ path, name string }{
+		{"deals/1", ""},
+		{"deals/1", "a/b"},
+		{"deals/1", ".."},
+		{"../deals", "data"},
+		{"deals/1", "data.sha256"},
+	} {
+		require.Error(t, s.UploadFile(c.path, c.name, []byte("hello")), "%s/%s", c.path, c.name)
+	}
+}
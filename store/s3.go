@@ -0,0 +1,204 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/medibloc/panacea-doracle/config"
+)
+
+// headerAmzMetaSHA256 is the user metadata of objects, which contains the hex-encoded SHA256 hash of the object.
+// It is a check against corruption only, because anyone who can put the object can also set the metadata.
+const headerAmzMetaSHA256 = "X-Amz-Meta-Sha256"
+
+var _ Storage = &S3Storage{}
+
+// S3Storage stores files as objects in a bucket of an S3-compatible storage (e.g. AWS S3, MinIO).
+// The key of an object is the path joined with the name.
+type S3Storage struct {
+	endpoint    *url.URL
+	bucket      string
+	pathStyle   bool
+	credentials s3Credentials
+	client      *http.Client
+}
+
+// NewS3Storage creates a S3Storage with the config.
+func NewS3Storage(conf config.StorageConfig) (*S3Storage, error) {
+	endpoint, err := url.Parse(conf.S3Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3-endpoint: %w", err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("invalid s3-endpoint: %s", conf.S3Endpoint)
+	}
+
+	return &S3Storage{
+		endpoint:  endpoint,
+		bucket:    conf.S3Bucket,
+		pathStyle: conf.S3PathStyle,
+		credentials: s3Credentials{
+			accessKeyID:     conf.S3AccessKeyID,
+			secretAccessKey: conf.S3SecretAccessKey,
+			region:          conf.S3Region,
+		},
+		client: &http.Client{Timeout: conf.S3Timeout},
+	}, nil
+}
+
+// WithBucket returns a S3Storage of another bucket with the same endpoint and credentials.
+func (s *S3Storage) WithBucket(bucket string) *S3Storage {
+	copied := *s
+	copied.bucket = bucket
+	return &copied
+}
+
+func (s *S3Storage) UploadFile(path, name string, data []byte) error {
+	key, err := objectKey(path, name)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(data)
+	return s.putObject(context.Background(), key, bytes.NewReader(data), int64(len(data)), hash[:])
+}
+
+func (s *S3Storage) DownloadFile(path, name string) ([]byte, error) {
+	return downloadAll(context.Background(), s, path, name)
+}
+
+// Upload writes the data to a temp file while hashing it, and then puts the object with the hash.
+// The hash is required before sending the object, so that the server can check the integrity of the object.
+func (s *S3Storage) Upload(ctx context.Context, path, name string, r io.Reader) ([]byte, error) {
+	key, err := objectKey(path, name)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpFile, err := os.CreateTemp("", "s3-upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmpFile, h), &contextReader{ctx: ctx, r: r})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data: %w", err)
+	}
+	if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek temp file: %w", err)
+	}
+
+	hash := h.Sum(nil)
+	if err := s.putObject(ctx, key, tmpFile, size, hash); err != nil {
+		return nil, err
+	}
+	return hash, nil
+}
+
+func (s *S3Storage) Download(ctx context.Context, path, name string) (io.ReadCloser, error) {
+	key, err := objectKey(path, name)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.credentials.sign(req, emptyPayloadHash, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("failed to get object %s: %w", key, responseError(resp))
+	}
+
+	var expectedHash []byte
+	if hashHex := resp.Header.Get(headerAmzMetaSHA256); hashHex != "" {
+		expectedHash, err = hex.DecodeString(hashHex)
+		if err != nil {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("invalid hash of object %s: %w", key, err)
+		}
+	}
+
+	return newHashVerifyingReader(resp.Body, expectedHash), nil
+}
+
+func (s *S3Storage) putObject(ctx context.Context, key string, body io.Reader, size int64, hash []byte) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set(headerAmzMetaSHA256, hex.EncodeToString(hash))
+	s.credentials.sign(req, hex.EncodeToString(hash), time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to put object %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to put object %s: %w", key, responseError(resp))
+	}
+	return nil
+}
+
+func (s *S3Storage) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	u := *s.endpoint
+	objectPath := "/" + key
+	if s.pathStyle {
+		objectPath = "/" + s.bucket + objectPath
+	} else {
+		u.Host = s.bucket + "." + u.Host
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + objectPath
+	u.RawPath = awsURIEncode(u.Path, false)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	return req, nil
+}
+
+// s3Error is the error response of S3-compatible storages.
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	var s3Err s3Error
+	if err := xml.Unmarshal(body, &s3Err); err != nil || s3Err.Code == "" {
+		s3Err.Code = http.StatusText(resp.StatusCode)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrNotFound, s3Err.Code)
+	}
+	if s3Err.Message != "" {
+		return fmt.Errorf("status %d: %s: %s", resp.StatusCode, s3Err.Code, s3Err.Message)
+	}
+	return fmt.Errorf("status %d: %s", resp.StatusCode, s3Err.Code)
+}
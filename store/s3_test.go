@@ -0,0 +1,143 @@
+package store_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/medibloc/panacea-doracle/config"
+	"github.com/medibloc/panacea-doracle/store"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testS3Bucket      = "doracle"
+	testS3AccessKeyID = "minioadmin"
+)
+
+// fakeS3 imitates the object API of S3-compatible storages like MinIO, with path-style requests.
+// Like them, it rejects objects which don't match the x-amz-content-sha256 header.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	meta    map[string]string
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	f := &fakeS3{objects: map[string][]byte{}, meta: map[string]string{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential="+testS3AccessKeyID+"/") {
+		writeS3Error(w, http.StatusForbidden, "AccessDenied")
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/"+testS3Bucket+"/")
+	if key == r.URL.Path {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		hash := sha256.Sum256(data)
+		if hex.EncodeToString(hash[:]) != r.Header.Get("X-Amz-Content-Sha256") {
+			writeS3Error(w, http.StatusBadRequest, "XAmzContentSHA256Mismatch")
+			return
+		}
+		f.objects[key] = data
+		f.meta[key] = r.Header.Get("X-Amz-Meta-Sha256")
+	case http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("X-Amz-Meta-Sha256", f.meta[key])
+		_, _ = w.Write(data)
+	default:
+		writeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+func writeS3Error(w http.ResponseWriter, statusCode int, code string) {
+	w.WriteHeader(statusCode)
+	_, _ = fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
+
+func newTestS3Storage(t *testing.T, endpoint, accessKeyID string) *store.S3Storage {
+	s, err := store.NewS3Storage(config.StorageConfig{
+		S3Endpoint:        endpoint,
+		S3Region:          "us-east-1",
+		S3Bucket:          testS3Bucket,
+		S3AccessKeyID:     accessKeyID,
+		S3SecretAccessKey: "minioadmin",
+		S3PathStyle:       true,
+		S3Timeout:         10 * time.Second,
+	})
+	require.NoError(t, err)
+	return s
+}
+
+func TestS3Storage(t *testing.T) {
+	f, server := newFakeS3(t)
+	s := newTestS3Storage(t, server.URL, testS3AccessKeyID)
+
+	data := []byte("hello")
+	hash, err := s.Upload(context.Background(), "deals/1", "data", bytes.NewReader(data))
+	require.NoError(t, err)
+	expectedHash := sha256.Sum256(data)
+	require.Equal(t, expectedHash[:], hash)
+	require.Equal(t, data, f.objects["deals/1/data"])
+
+	downloaded, err := s.DownloadFile("deals/1", "data")
+	require.NoError(t, err)
+	require.Equal(t, data, downloaded)
+
+	require.NoError(t, s.UploadFile("deals/1", "data 2", []byte("world")))
+	downloaded, err = s.DownloadFile("deals/1", "data 2")
+	require.NoError(t, err)
+	require.Equal(t, []byte("world"), downloaded)
+
+	_, err = s.DownloadFile("deals/1", "not-exist")
+	require.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func TestS3StorageHashMismatch(t *testing.T) {
+	f, server := newFakeS3(t)
+	s := newTestS3Storage(t, server.URL, testS3AccessKeyID)
+
+	require.NoError(t, s.UploadFile("deals/1", "data", []byte("hello")))
+	f.objects["deals/1/data"] = []byte("tampered")
+
+	_, err := s.DownloadFile("deals/1", "data")
+	require.ErrorIs(t, err, store.ErrHashMismatch)
+}
+
+func TestS3StorageError(t *testing.T) {
+	_, server := newFakeS3(t)
+	s := newTestS3Storage(t, server.URL, "invalid")
+
+	err := s.UploadFile("deals/1", "data", []byte("hello"))
+	require.ErrorContains(t, err, "AccessDenied")
+}
@@ -0,0 +1,28 @@
+package sgxdb
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	tmdb "github.com/tendermint/tm-db"
+)
+
+// sgxDBBatch seals values when they are added to the batch,
+// so that both Write and WriteSync write only sealed values.
+type sgxDBBatch struct {
+	tmdb.Batch
+	sealer Sealer
+}
+
+func (sbatch *sgxDBBatch) Set(key, value []byte) error {
+	if value == nil {
+		return ErrValueNil
+	}
+
+	log.Debug("sealing before writing to db in batch")
+	sealValue, err := sbatch.sealer.Seal(value)
+	if err != nil {
+		return fmt.Errorf("failed to seal value: %w", err)
+	}
+	return sbatch.Batch.Set(key, sealValue)
+}
@@ -0,0 +1,130 @@
+// Package sgxdb implements a DB for panacea-doracle which wraps any tm-db backend.
+// Values are sealed on every write path, and unsealed on every read path including iterators.
+
+package sgxdb
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	tmdb "github.com/tendermint/tm-db"
+)
+
+var _ tmdb.DB = (*SgxDB)(nil)
+
+type SgxDB struct {
+	tmdb.DB
+	sealer Sealer
+}
+
+// NewSgxDB wraps the db, so that all values are sealed with the unique key of the enclave in the db.
+func NewSgxDB(db tmdb.DB) *SgxDB {
+	return NewSgxDBWithSealer(db, enclaveSealer{})
+}
+
+// NewSgxDBWithSealer wraps the db, so that all values are sealed by the sealer in the db.
+func NewSgxDBWithSealer(db tmdb.DB, sealer Sealer) *SgxDB {
+	return &SgxDB{
+		DB:     db,
+		sealer: sealer,
+	}
+}
+
+// NewSgxDBWithBackend creates a db using the tm-db backend, and wraps it with the sealer.
+// Some backends are available only if the binary is built with their build tags (e.g. badgerdb).
+func NewSgxDBWithBackend(name string, backend tmdb.BackendType, dir string, sealer Sealer) (*SgxDB, error) {
+	db, err := tmdb.NewDB(name, backend, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s db: %w", backend, err)
+	}
+
+	return NewSgxDBWithSealer(db, sealer), nil
+}
+
+func (sdb *SgxDB) Set(key, value []byte) error {
+	log.Debug("sealing before writing to db")
+	sealValue, err := sdb.seal(value)
+	if err != nil {
+		return err
+	}
+	return sdb.DB.Set(key, sealValue)
+}
+
+func (sdb *SgxDB) SetSync(key, value []byte) error {
+	log.Debug("sealing before writing to db")
+	sealValue, err := sdb.seal(value)
+	if err != nil {
+		return err
+	}
+	return sdb.DB.SetSync(key, sealValue)
+}
+
+func (sdb *SgxDB) Get(key []byte) ([]byte, error) {
+	val, err := sdb.DB.Get(key)
+	if err != nil {
+		return nil, err
+	} else if val == nil {
+		return nil, nil
+	}
+
+	log.Debug("unsealing after reading from db")
+	return sdb.unseal(val)
+}
+
+func (sdb *SgxDB) Iterator(start, end []byte) (tmdb.Iterator, error) {
+	it, err := sdb.DB.Iterator(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return newSgxDBIterator(it, sdb.sealer), nil
+}
+
+func (sdb *SgxDB) ReverseIterator(start, end []byte) (tmdb.Iterator, error) {
+	it, err := sdb.DB.ReverseIterator(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return newSgxDBIterator(it, sdb.sealer), nil
+}
+
+func (sdb *SgxDB) NewBatch() tmdb.Batch {
+	batch := sdb.DB.NewBatch()
+	return &sgxDBBatch{
+		Batch:  batch,
+		sealer: sdb.sealer,
+	}
+}
+
+// Print prints all keys and unsealed values in the db.
+func (sdb *SgxDB) Print() error {
+	it, err := sdb.Iterator(nil, nil)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	for ; it.Valid(); it.Next() {
+		fmt.Printf("[%X]:\t[%X]\n", it.Key(), it.Value())
+	}
+	return it.Error()
+}
+
+func (sdb *SgxDB) seal(value []byte) ([]byte, error) {
+	if value == nil {
+		return nil, ErrValueNil
+	}
+
+	sealValue, err := sdb.sealer.Seal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seal value: %w", err)
+	}
+	return sealValue, nil
+}
+
+func (sdb *SgxDB) unseal(value []byte) ([]byte, error) {
+	unsealedVal, err := sdb.sealer.Unseal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unseal value from db: %w", err)
+	}
+	return unsealedVal, nil
+}
@@ -0,0 +1,235 @@
+package sgxdb
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	tmdb "github.com/tendermint/tm-db"
+)
+
+// softwareSealer seals values with AES-GCM, so that the db can be tested without SGX.
+type softwareSealer struct {
+	aead cipher.AEAD
+}
+
+func newSoftwareSealer(t *testing.T) *softwareSealer {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+
+	return &softwareSealer{aead: aead}
+}
+
+func (s *softwareSealer) Seal(data []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, data, nil), nil
+}
+
+func (s *softwareSealer) Unseal(data []byte) ([]byte, error) {
+	if len(data) < s.aead.NonceSize() {
+		return nil, errors.New("sealed data is too short")
+	}
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	return s.aead.Open(nil, nonce, ciphertext, nil)
+}
+
+func TestSgxDBConformance(t *testing.T) {
+	backends := map[string]func(t *testing.T) tmdb.DB{
+		"memdb": func(t *testing.T) tmdb.DB {
+			return tmdb.NewMemDB()
+		},
+		"goleveldb": func(t *testing.T) tmdb.DB {
+			db, err := tmdb.NewGoLevelDB("test", t.TempDir())
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = db.Close() })
+			return db
+		},
+	}
+
+	sealers := map[string]func(t *testing.T) Sealer{
+		"software": func(t *testing.T) Sealer {
+			return newSoftwareSealer(t)
+		},
+		"envelope": func(t *testing.T) Sealer {
+			keyring, err := NewDataKeyring()
+			require.NoError(t, err)
+			sealer, err := NewEnvelopeSealer(keyring, nil)
+			require.NoError(t, err)
+			return sealer
+		},
+	}
+
+	for backendName, newBackend := range backends {
+		for sealerName, newSealer := range sealers {
+			t.Run(backendName+"/"+sealerName, func(t *testing.T) {
+				testSgxDBConformance(t, newBackend, newSealer)
+			})
+		}
+	}
+}
+
+func testSgxDBConformance(t *testing.T, newBackend func(t *testing.T) tmdb.DB, newSealer func(t *testing.T) Sealer) {
+	setup := func(t *testing.T) (*SgxDB, tmdb.DB) {
+		backend := newBackend(t)
+		return NewSgxDBWithSealer(backend, newSealer(t)), backend
+	}
+
+	t.Run("SetAndGet", func(t *testing.T) {
+		db, backend := setup(t)
+
+		require.NoError(t, db.Set([]byte("a"), []byte("value-a")))
+		require.NoError(t, db.SetSync([]byte("b"), []byte("value-b")))
+
+		requireValue(t, db, "a", "value-a")
+		requireValue(t, db, "b", "value-b")
+		requireSealed(t, backend, "a", "value-a")
+		requireSealed(t, backend, "b", "value-b")
+
+		value, err := db.Get([]byte("c"))
+		require.NoError(t, err)
+		require.Nil(t, value)
+
+		has, err := db.Has([]byte("a"))
+		require.NoError(t, err)
+		require.True(t, has)
+
+		require.ErrorIs(t, db.Set([]byte("c"), nil), ErrValueNil)
+		require.ErrorIs(t, db.SetSync([]byte("c"), nil), ErrValueNil)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		db, _ := setup(t)
+
+		require.NoError(t, db.Set([]byte("a"), []byte("value-a")))
+		require.NoError(t, db.Set([]byte("b"), []byte("value-b")))
+		require.NoError(t, db.Delete([]byte("a")))
+		require.NoError(t, db.DeleteSync([]byte("b")))
+
+		for _, key := range []string{"a", "b"} {
+			has, err := db.Has([]byte(key))
+			require.NoError(t, err)
+			require.False(t, has)
+		}
+	})
+
+	t.Run("Iterator", func(t *testing.T) {
+		db, _ := setup(t)
+		setValues(t, db, "a", "b", "c", "d")
+
+		it, err := db.Iterator(nil, nil)
+		require.NoError(t, err)
+		requireIterator(t, it, "a", "b", "c", "d")
+
+		it, err = db.Iterator([]byte("b"), []byte("d"))
+		require.NoError(t, err)
+		requireIterator(t, it, "b", "c")
+
+		it, err = db.Iterator([]byte("x"), nil)
+		require.NoError(t, err)
+		requireIterator(t, it)
+	})
+
+	t.Run("ReverseIterator", func(t *testing.T) {
+		db, _ := setup(t)
+		setValues(t, db, "a", "b", "c", "d")
+
+		it, err := db.ReverseIterator(nil, nil)
+		require.NoError(t, err)
+		requireIterator(t, it, "d", "c", "b", "a")
+
+		it, err = db.ReverseIterator([]byte("b"), []byte("d"))
+		require.NoError(t, err)
+		requireIterator(t, it, "c", "b")
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		db, backend := setup(t)
+		require.NoError(t, db.Set([]byte("x"), []byte("value-x")))
+
+		batch := db.NewBatch()
+		require.NoError(t, batch.Set([]byte("a"), []byte("value-a")))
+		require.NoError(t, batch.Delete([]byte("x")))
+		require.ErrorIs(t, batch.Set([]byte("b"), nil), ErrValueNil)
+		require.NoError(t, batch.Write())
+		require.NoError(t, batch.Close())
+
+		batch = db.NewBatch()
+		require.NoError(t, batch.Set([]byte("b"), []byte("value-b")))
+		require.NoError(t, batch.WriteSync())
+		require.NoError(t, batch.Close())
+
+		requireValue(t, db, "a", "value-a")
+		requireValue(t, db, "b", "value-b")
+		requireSealed(t, backend, "a", "value-a")
+		requireSealed(t, backend, "b", "value-b")
+
+		has, err := db.Has([]byte("x"))
+		require.NoError(t, err)
+		require.False(t, has)
+	})
+
+	t.Run("UnsealFailure", func(t *testing.T) {
+		db, backend := setup(t)
+		setValues(t, db, "a")
+		// the value which is not sealed by the sealer cannot be unsealed
+		require.NoError(t, backend.Set([]byte("b"), []byte("not-sealed")))
+
+		_, err := db.Get([]byte("b"))
+		require.ErrorContains(t, err, "failed to unseal")
+
+		it, err := db.Iterator(nil, nil)
+		require.NoError(t, err)
+		defer it.Close()
+
+		require.True(t, it.Valid())
+		require.Equal(t, []byte("value-a"), it.Value())
+		it.Next()
+		require.False(t, it.Valid())
+		require.ErrorContains(t, it.Error(), "failed to unseal")
+	})
+}
+
+func setValues(t *testing.T, db tmdb.DB, keys ...string) {
+	for _, key := range keys {
+		require.NoError(t, db.Set([]byte(key), []byte("value-"+key)))
+	}
+}
+
+func requireValue(t *testing.T, db tmdb.DB, key, expected string) {
+	value, err := db.Get([]byte(key))
+	require.NoError(t, err)
+	require.Equal(t, []byte(expected), value)
+}
+
+// requireSealed checks that the backend doesn't contain the plain value.
+func requireSealed(t *testing.T, backend tmdb.DB, key, plain string) {
+	value, err := backend.Get([]byte(key))
+	require.NoError(t, err)
+	require.NotNil(t, value)
+	require.False(t, bytes.Contains(value, []byte(plain)))
+}
+
+func requireIterator(t *testing.T, it tmdb.Iterator, expectedKeys ...string) {
+	defer it.Close()
+
+	var keys []string
+	for ; it.Valid(); it.Next() {
+		keys = append(keys, string(it.Key()))
+		require.Equal(t, []byte("value-"+string(it.Key())), it.Value())
+	}
+	require.NoError(t, it.Error())
+	require.Equal(t, expectedKeys, keys)
+}
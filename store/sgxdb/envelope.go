@@ -0,0 +1,156 @@
+package sgxdb
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+)
+
+// envelopeMagic is the prefix of values encrypted by EnvelopeSealer.
+// The values without this prefix are regarded as sealed by the legacy sealer.
+var envelopeMagic = []byte("DKE1")
+
+// envelopeHeaderSize is the size of the magic and the key version.
+var envelopeHeaderSize = len(envelopeMagic) + 4
+
+var _ Sealer = (*EnvelopeSealer)(nil)
+
+// EnvelopeSealer encrypts values with AES-GCM under a data key in the DataKeyring,
+// instead of deriving a seal key of the enclave for every value.
+// Only the keyring is sealed by the enclave, once when it is created or rotated.
+//
+// An encrypted value is: magic(4) | key version(4, big endian) | nonce | ciphertext.
+// The magic and the key version are authenticated as additional data.
+//
+// Keys in the db are not encrypted, because the DB must keep the order of keys for range scans.
+// For the light client store, keys consist of the chain ID and heights, which are public anyway.
+type EnvelopeSealer struct {
+	currentVersion uint32
+	aeads          map[uint32]cipher.AEAD
+	legacy         Sealer
+}
+
+// NewEnvelopeSealer creates an EnvelopeSealer with all data keys in the keyring.
+// If legacy is not nil, it unseals values which were sealed before the envelope encryption was introduced.
+func NewEnvelopeSealer(keyring *DataKeyring, legacy Sealer) (*EnvelopeSealer, error) {
+	if err := keyring.validate(); err != nil {
+		return nil, fmt.Errorf("invalid data keyring: %w", err)
+	}
+
+	aeads := make(map[uint32]cipher.AEAD, len(keyring.Keys))
+	for version, key := range keyring.Keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cipher of data key version(%d): %w", version, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GCM of data key version(%d): %w", version, err)
+		}
+		aeads[version] = aead
+	}
+
+	return &EnvelopeSealer{
+		currentVersion: keyring.CurrentVersion,
+		aeads:          aeads,
+		legacy:         legacy,
+	}, nil
+}
+
+func (s *EnvelopeSealer) Seal(data []byte) ([]byte, error) {
+	aead := s.aeads[s.currentVersion]
+
+	header := make([]byte, envelopeHeaderSize)
+	copy(header, envelopeMagic)
+	binary.BigEndian.PutUint32(header[len(envelopeMagic):], s.currentVersion)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := make([]byte, 0, len(header)+len(nonce)+len(data)+aead.Overhead())
+	sealed = append(sealed, header...)
+	sealed = append(sealed, nonce...)
+	return aead.Seal(sealed, nonce, data, header), nil
+}
+
+func (s *EnvelopeSealer) Unseal(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, envelopeMagic) {
+		if s.legacy == nil {
+			return nil, fmt.Errorf("the value is not encrypted by a data key")
+		}
+		return s.legacy.Unseal(data)
+	}
+
+	version, err := envelopeKeyVersion(data)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, ok := s.aeads[version]
+	if !ok {
+		return nil, fmt.Errorf("no data key of version(%d)", version)
+	}
+
+	if len(data) < envelopeHeaderSize+aead.NonceSize() {
+		return nil, fmt.Errorf("the encrypted value is too short")
+	}
+	header := data[:envelopeHeaderSize]
+	nonce := data[envelopeHeaderSize : envelopeHeaderSize+aead.NonceSize()]
+	ciphertext := data[envelopeHeaderSize+aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt with data key version(%d): %w", version, err)
+	}
+	return plaintext, nil
+}
+
+// envelopeKeyVersion returns the version of the data key which encrypted the value.
+func envelopeKeyVersion(data []byte) (uint32, error) {
+	if len(data) < envelopeHeaderSize || !bytes.HasPrefix(data, envelopeMagic) {
+		return 0, fmt.Errorf("invalid header of the encrypted value")
+	}
+	return binary.BigEndian.Uint32(data[len(envelopeMagic):envelopeHeaderSize]), nil
+}
+
+// Reencrypt re-encrypts all values in the db with the current sealer of the db.
+// After rotating the data key, this makes all values encrypted by the new data key,
+// so that old data keys can be pruned.
+func Reencrypt(db *SgxDB) (int, error) {
+	it, err := db.Iterator(nil, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var keys, values [][]byte
+	for ; it.Valid(); it.Next() {
+		keys = append(keys, append([]byte(nil), it.Key()...))
+		values = append(values, append([]byte(nil), it.Value()...))
+	}
+	if err := it.Error(); err != nil {
+		_ = it.Close()
+		return 0, err
+	}
+	if err := it.Close(); err != nil {
+		return 0, err
+	}
+
+	batch := db.NewBatch()
+	defer batch.Close()
+
+	for i := range keys {
+		if err := batch.Set(keys[i], values[i]); err != nil {
+			return 0, err
+		}
+	}
+	if err := batch.WriteSync(); err != nil {
+		return 0, fmt.Errorf("failed to write re-encrypted values: %w", err)
+	}
+
+	return len(keys), nil
+}
@@ -0,0 +1,139 @@
+package sgxdb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	tmdb "github.com/tendermint/tm-db"
+)
+
+func TestEnvelopeSealerKeyVersion(t *testing.T) {
+	keyring, err := NewDataKeyring()
+	require.NoError(t, err)
+
+	sealer, err := NewEnvelopeSealer(keyring, nil)
+	require.NoError(t, err)
+
+	sealed, err := sealer.Seal([]byte("value"))
+	require.NoError(t, err)
+
+	version, err := envelopeKeyVersion(sealed)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), version)
+
+	// the key version is authenticated
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(envelopeMagic)+3] = 2
+	require.NoError(t, keyring.Rotate())
+	sealer, err = NewEnvelopeSealer(keyring, nil)
+	require.NoError(t, err)
+	_, err = sealer.Unseal(tampered)
+	require.ErrorContains(t, err, "failed to decrypt with data key version(2)")
+
+	_, err = sealer.Unseal([]byte("not-encrypted"))
+	require.ErrorContains(t, err, "not encrypted by a data key")
+}
+
+func TestEnvelopeSealerRotation(t *testing.T) {
+	backend := tmdb.NewMemDB()
+
+	keyring, err := NewDataKeyring()
+	require.NoError(t, err)
+	sealer, err := NewEnvelopeSealer(keyring, nil)
+	require.NoError(t, err)
+	setValues(t, NewSgxDBWithSealer(backend, sealer), "a", "b")
+
+	// values encrypted by the old data key are still readable after rotation
+	require.NoError(t, keyring.Rotate())
+	sealer, err = NewEnvelopeSealer(keyring, nil)
+	require.NoError(t, err)
+	db := NewSgxDBWithSealer(backend, sealer)
+	requireValue(t, db, "a", "value-a")
+
+	count, err := Reencrypt(db)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	for _, key := range []string{"a", "b"} {
+		sealed, err := backend.Get([]byte(key))
+		require.NoError(t, err)
+		version, err := envelopeKeyVersion(sealed)
+		require.NoError(t, err)
+		require.Equal(t, uint32(2), version)
+	}
+
+	// after re-encryption, the old data key is not needed anymore
+	keyring.PruneOldVersions()
+	require.Len(t, keyring.Keys, 1)
+	sealer, err = NewEnvelopeSealer(keyring, nil)
+	require.NoError(t, err)
+	db = NewSgxDBWithSealer(backend, sealer)
+	requireValue(t, db, "a", "value-a")
+	requireValue(t, db, "b", "value-b")
+}
+
+func TestEnvelopeSealerLegacy(t *testing.T) {
+	backend := tmdb.NewMemDB()
+	legacy := newSoftwareSealer(t)
+	setValues(t, NewSgxDBWithSealer(backend, legacy), "a")
+
+	keyring, err := NewDataKeyring()
+	require.NoError(t, err)
+	sealer, err := NewEnvelopeSealer(keyring, legacy)
+	require.NoError(t, err)
+
+	db := NewSgxDBWithSealer(backend, sealer)
+	requireValue(t, db, "a", "value-a")
+
+	// the legacy value is encrypted by the data key after re-encryption
+	_, err = Reencrypt(db)
+	require.NoError(t, err)
+	sealed, err := backend.Get([]byte("a"))
+	require.NoError(t, err)
+	_, err = envelopeKeyVersion(sealed)
+	require.NoError(t, err)
+}
+
+func TestLoadOrCreateDataKeyring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data-key.sealed")
+	sealer := newSoftwareSealer(t)
+
+	db := tmdb.NewMemDB()
+
+	keyring, err := LoadOrCreateDataKeyring(path, sealer, db)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), keyring.CurrentVersion)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := LoadOrCreateDataKeyring(path, sealer, db)
+	require.NoError(t, err)
+	require.Equal(t, keyring, loaded)
+
+	// the keyring sealed by another sealer cannot be loaded
+	_, err = LoadDataKeyring(path, newSoftwareSealer(t))
+	require.ErrorContains(t, err, "failed to unseal data keyring")
+}
+
+func TestLoadOrCreateDataKeyringWithEncryptedValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data-key.sealed")
+	sealer := newSoftwareSealer(t)
+
+	db := tmdb.NewMemDB()
+	keyring, err := LoadOrCreateDataKeyring(path, sealer, db)
+	require.NoError(t, err)
+	envelopeSealer, err := NewEnvelopeSealer(keyring, sealer)
+	require.NoError(t, err)
+	require.NoError(t, NewSgxDBWithSealer(db, envelopeSealer).Set([]byte("key"), []byte("value")))
+
+	// the values in the db can't be decrypted by a new keyring
+	require.NoError(t, os.Remove(path))
+	_, err = LoadOrCreateDataKeyring(path, sealer, db)
+	require.ErrorContains(t, err, "restore the file")
+	_, err = os.Stat(path)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
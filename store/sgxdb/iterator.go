@@ -0,0 +1,68 @@
+package sgxdb
+
+import (
+	"fmt"
+
+	tmdb "github.com/tendermint/tm-db"
+)
+
+var _ tmdb.Iterator = (*sgxDBIterator)(nil)
+
+// sgxDBIterator unseals the value at each position of the underlying iterator.
+// If a value cannot be unsealed, the iterator becomes invalid and Error returns the failure.
+type sgxDBIterator struct {
+	tmdb.Iterator
+	sealer Sealer
+
+	value []byte
+	err   error
+}
+
+func newSgxDBIterator(it tmdb.Iterator, sealer Sealer) *sgxDBIterator {
+	sit := &sgxDBIterator{
+		Iterator: it,
+		sealer:   sealer,
+	}
+	sit.unsealValue()
+	return sit
+}
+
+func (sit *sgxDBIterator) Valid() bool {
+	return sit.err == nil && sit.Iterator.Valid()
+}
+
+func (sit *sgxDBIterator) Next() {
+	if !sit.Valid() {
+		panic("iterator is invalid")
+	}
+	sit.Iterator.Next()
+	sit.unsealValue()
+}
+
+func (sit *sgxDBIterator) Value() []byte {
+	if !sit.Valid() {
+		panic("iterator is invalid")
+	}
+	return sit.value
+}
+
+func (sit *sgxDBIterator) Error() error {
+	if sit.err != nil {
+		return sit.err
+	}
+	return sit.Iterator.Error()
+}
+
+func (sit *sgxDBIterator) unsealValue() {
+	sit.value = nil
+	if !sit.Iterator.Valid() {
+		return
+	}
+
+	value, err := sit.sealer.Unseal(sit.Iterator.Value())
+	if err != nil {
+		sit.err = fmt.Errorf("failed to unseal value of key(%X) from db: %w", sit.Iterator.Key(), err)
+		return
+	}
+	sit.value = value
+}
@@ -0,0 +1,156 @@
+package sgxdb
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/medibloc/panacea-doracle/sgx"
+	tmdb "github.com/tendermint/tm-db"
+)
+
+// dataKeySize is the size of an AES-256 data key.
+const dataKeySize = 32
+
+// DataKeyring contains all versions of data keys which encrypt values in the db.
+// Old versions are kept after rotation, so that values encrypted by them can be read until they are re-encrypted.
+type DataKeyring struct {
+	CurrentVersion uint32            `json:"current_version"`
+	Keys           map[uint32][]byte `json:"keys"`
+}
+
+// NewDataKeyring generates a keyring with a new data key of version 1.
+func NewDataKeyring() (*DataKeyring, error) {
+	keyring := &DataKeyring{
+		Keys: make(map[uint32][]byte),
+	}
+	if err := keyring.Rotate(); err != nil {
+		return nil, err
+	}
+	return keyring, nil
+}
+
+// Rotate generates a new data key, and makes it the current version.
+func (k *DataKeyring) Rotate() error {
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	k.CurrentVersion++
+	k.Keys[k.CurrentVersion] = key
+	return nil
+}
+
+// PruneOldVersions removes all data keys except the current version.
+// This must be called only after all values are re-encrypted with the current version.
+func (k *DataKeyring) PruneOldVersions() {
+	for version := range k.Keys {
+		if version != k.CurrentVersion {
+			delete(k.Keys, version)
+		}
+	}
+}
+
+func (k *DataKeyring) validate() error {
+	if len(k.Keys) == 0 {
+		return errors.New("no data key in the keyring")
+	}
+	if _, ok := k.Keys[k.CurrentVersion]; !ok {
+		return fmt.Errorf("no data key of the current version(%d)", k.CurrentVersion)
+	}
+	for version, key := range k.Keys {
+		if len(key) != dataKeySize {
+			return fmt.Errorf("invalid size of data key version(%d): %d", version, len(key))
+		}
+	}
+	return nil
+}
+
+// LoadOrCreateDataKeyring loads the keyring sealed in the file.
+// If the file doesn't exist, it creates a new keyring and writes it to the file,
+// but only if the db has no value encrypted by a data key. Otherwise, those values would become unreadable.
+// The db must be the underlying db of SgxDB, not SgxDB itself.
+func LoadOrCreateDataKeyring(path string, sealer Sealer, db tmdb.DB) (*DataKeyring, error) {
+	keyring, err := LoadDataKeyring(path, sealer)
+	if err == nil {
+		return keyring, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	encrypted, err := hasEnvelopeValues(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check values in db: %w", err)
+	}
+	if encrypted {
+		return nil, fmt.Errorf("%s doesn't exist, but the db has values encrypted by its data key. restore the file from a backup", path)
+	}
+
+	keyring, err = NewDataKeyring()
+	if err != nil {
+		return nil, err
+	}
+	if err := keyring.Save(path, sealer); err != nil {
+		return nil, err
+	}
+	return keyring, nil
+}
+
+// hasEnvelopeValues returns true if any value in the db is encrypted by a data key.
+func hasEnvelopeValues(db tmdb.DB) (bool, error) {
+	it, err := db.Iterator(nil, nil)
+	if err != nil {
+		return false, err
+	}
+	defer it.Close()
+
+	for ; it.Valid(); it.Next() {
+		if bytes.HasPrefix(it.Value(), envelopeMagic) {
+			return true, nil
+		}
+	}
+	return false, it.Error()
+}
+
+// LoadDataKeyring loads the keyring sealed in the file.
+func LoadDataKeyring(path string, sealer Sealer) (*DataKeyring, error) {
+	sealed, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	keyringBz, err := sealer.Unseal(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unseal data keyring: %w", err)
+	}
+
+	var keyring DataKeyring
+	if err := json.Unmarshal(keyringBz, &keyring); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal data keyring: %w", err)
+	}
+	if err := keyring.validate(); err != nil {
+		return nil, fmt.Errorf("invalid data keyring: %w", err)
+	}
+
+	return &keyring, nil
+}
+
+// Save seals the keyring and writes it to the file atomically,
+// so that the keyring is never lost even if the process is terminated while writing.
+func (k *DataKeyring) Save(path string, sealer Sealer) error {
+	keyringBz, err := json.Marshal(k)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data keyring: %w", err)
+	}
+
+	sealed, err := sealer.Seal(keyringBz)
+	if err != nil {
+		return fmt.Errorf("failed to seal data keyring: %w", err)
+	}
+
+	return sgx.WriteFileAtomic(path, sealed, 0600)
+}
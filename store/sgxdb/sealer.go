@@ -0,0 +1,45 @@
+package sgxdb
+
+import (
+	"errors"
+
+	"github.com/medibloc/panacea-doracle/sgx"
+)
+
+// ErrValueNil is returned when a nil value is written, like the backends of tm-db.
+var ErrValueNil = errors.New("value cannot be nil")
+
+// Sealer seals values before they are written to the db, and unseals them after they are read from the db.
+type Sealer interface {
+	Seal(data []byte) ([]byte, error)
+	Unseal(data []byte) ([]byte, error)
+}
+
+var _ Sealer = enclaveSealer{}
+
+// EnclaveSealer returns a Sealer which seals values with the key of the current seal policy of the enclave.
+func EnclaveSealer() Sealer {
+	return enclaveSealer{}
+}
+
+// EnclaveSealerWithPolicy returns a Sealer which seals values with the key of the seal policy, regardless of the current one.
+func EnclaveSealerWithPolicy(policy string) Sealer {
+	return enclaveSealer{policy: policy}
+}
+
+// enclaveSealer seals values with the key of the enclave.
+// If the policy is empty, the current seal policy of the enclave is used.
+type enclaveSealer struct {
+	policy string
+}
+
+func (s enclaveSealer) Seal(data []byte) ([]byte, error) {
+	if s.policy != "" {
+		return sgx.SealWithPolicy(data, s.policy)
+	}
+	return sgx.Seal(data, true)
+}
+
+func (enclaveSealer) Unseal(data []byte) ([]byte, error) {
+	return sgx.Unseal(data, true)
+}
@@ -1,6 +1,112 @@
 package store
 
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/medibloc/panacea-doracle/config"
+)
+
+var (
+	// ErrNotFound is returned if the file doesn't exist in the storage.
+	ErrNotFound = errors.New("file not found")
+	// ErrHashMismatch is returned if the downloaded data doesn't match the SHA256 hash stored at the upload.
+	// The hash is stored next to the data, so it detects corruption, not tampering by who can write the storage.
+	ErrHashMismatch = errors.New("hash mismatch")
+)
+
+// Storage stores files, which are identified by a path and a name.
 type Storage interface {
 	UploadFile(path, name string, data []byte) error
 	DownloadFile(path, name string) ([]byte, error)
+
+	// Upload streams the data to the file, and returns the SHA256 hash of the data.
+	// The hash is stored with the file, so that corruption of the file can be detected at the download.
+	// Anyone who can rewrite the file can also rewrite the hash, so callers who need integrity must check
+	// the data against a hash they trust, such as the '#sha256=' of a URI or the data hash on the chain.
+	Upload(ctx context.Context, path, name string, r io.Reader) ([]byte, error)
+	// Download returns a reader of the file.
+	// The reader returns ErrHashMismatch at the end of the file, if the data doesn't match the hash stored at the upload.
+	Download(ctx context.Context, path, name string) (io.ReadCloser, error)
+}
+
+// NewStorage creates the storage selected by the config.
+// It returns nil if IPFS is selected, because IPFS is not a Storage but content-addressed.
+func NewStorage(conf *config.Config) (Storage, error) {
+	switch conf.Storage.Backend {
+	case config.StorageBackendIpfs:
+		return nil, nil
+	case config.StorageBackendFileSystem:
+		return NewFileSystemStorage(conf.AbsStorageFileSystemDir())
+	case config.StorageBackendS3:
+		return NewS3Storage(conf.Storage)
+	default:
+		return nil, fmt.Errorf("invalid storage backend: %s", conf.Storage.Backend)
+	}
+}
+
+// objectKey returns the key of the file, which is the path joined with the name.
+// The path and the name must not escape the root of the storage.
+func objectKey(filePath, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid file name: %q", name)
+	}
+	for _, segment := range strings.Split(filePath, "/") {
+		if segment == ".." || strings.Contains(segment, `\`) {
+			return "", fmt.Errorf("invalid file path: %q", filePath)
+		}
+	}
+
+	key := strings.TrimPrefix(path.Join("/", filePath, name), "/")
+	return key, nil
+}
+
+// downloadAll reads all data of the file from the storage.
+func downloadAll(ctx context.Context, s Storage, filePath, name string) ([]byte, error) {
+	r, err := s.Download(ctx, filePath, name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// hashVerifyingReader computes the SHA256 hash of the data while reading,
+// and returns ErrHashMismatch at the end of the data if it doesn't match the expected hash.
+type hashVerifyingReader struct {
+	io.ReadCloser
+	hash         hash.Hash
+	expectedHash []byte
+}
+
+func newHashVerifyingReader(r io.ReadCloser, expectedHash []byte) io.ReadCloser {
+	if expectedHash == nil {
+		return r
+	}
+	return &hashVerifyingReader{
+		ReadCloser:   r,
+		hash:         sha256.New(),
+		expectedHash: expectedHash,
+	}
+}
+
+func (r *hashVerifyingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	if errors.Is(err, io.EOF) && !bytes.Equal(r.hash.Sum(nil), r.expectedHash) {
+		return n, fmt.Errorf("%w: expected(%X), got(%X)", ErrHashMismatch, r.expectedHash, r.hash.Sum(nil))
+	}
+	return n, err
 }